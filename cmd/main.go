@@ -1,6 +1,8 @@
 package main
 
 import (
+	"os"
+
 	"github.com/ilyakaznacheev/cleanenv"
 
 	"github.com/Kontentski/develops-today-task/config"
@@ -17,7 +19,15 @@ func main() {
 	if err != nil {
 		logger.Fatal("failed to read env", "err", err)
 	}
+	if err := cfg.Validate(); err != nil {
+		logger.Fatal("invalid config", "err", err)
+	}
 	logger.Info("read config", "config", cfg)
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		app.Migrate(&cfg, os.Args[2:])
+		return
+	}
+
 	app.Run(&cfg)
 }
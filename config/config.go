@@ -1,29 +1,144 @@
 package config
 
+import (
+	"fmt"
+	"time"
+)
+
 type (
 	Config struct {
 		HTTP
+		Auth
+		RateLimit
+		Idempotency
 		Log
+		Storage
 		PostgreSQL
 		CatAPI
+		Salary
+		Payroll
+		Breeds
 	}
 
 	HTTP struct {
 		Port string `env:"HTTP_PORT"`
+		// TrustedProxies are IPs or CIDRs of proxies which X-Forwarded-For headers give the client IP.
+		// No proxy is trusted by default, so clients cannot pick their IP for rate limits.
+		TrustedProxies []string `env:"HTTP_TRUSTED_PROXIES" env-separator:","`
+	}
+
+	Auth struct {
+		// JWTSecret verifies HS256 tokens, it must be at least 32 bytes long.
+		// It is excluded from JSON, so it is not logged with the config.
+		JWTSecret string `env:"AUTH_JWT_SECRET" json:"-"`
+		// JWTPublicKeyFile is a PEM encoded RSA public key verifying RS256 tokens.
+		JWTPublicKeyFile string `env:"AUTH_JWT_PUBLIC_KEY_FILE"`
+		// JWTIssuer and JWTAudience are checked if set.
+		JWTIssuer   string `env:"AUTH_JWT_ISSUER"`
+		JWTAudience string `env:"AUTH_JWT_AUDIENCE"`
+	}
+
+	RateLimit struct {
+		// Read and Write are limits of safe and other requests of a client to a route group
+		// given as <requests>/<period>, e.g. 300/1m, "0" is unlimited. Requests is also the burst.
+		Read  string `env:"RATE_LIMIT_READ" env-default:"300/1m"`
+		Write string `env:"RATE_LIMIT_WRITE" env-default:"60/1m"`
+		// IP limits all requests of a client IP before authentication, so invalid credentials are throttled too.
+		IP string `env:"RATE_LIMIT_IP" env-default:"600/1m"`
+		// Groups overrides limits of route groups, e.g. spycats.write:10/1m,payroll.read:10/1m.
+		Groups map[string]string `env:"RATE_LIMIT_GROUPS" env-separator:","`
+	}
+
+	Idempotency struct {
+		// TTL is how long responses of requests with an Idempotency-Key are replayed.
+		TTL time.Duration `env:"IDEMPOTENCY_TTL" env-default:"24h"`
+		// LockTimeout is how long a request holds its key, a key still in progress after it is abandoned.
+		LockTimeout time.Duration `env:"IDEMPOTENCY_LOCK_TIMEOUT" env-default:"2m"`
+		// PurgeInterval is how often expired idempotency keys are deleted.
+		PurgeInterval time.Duration `env:"IDEMPOTENCY_PURGE_INTERVAL" env-default:"1h"`
 	}
 
 	Log struct {
 		Level string `env:"LOG_LEVEL"`
 	}
 
+	Storage struct {
+		// Driver selects the storage backend: postgres or memory.
+		Driver string `env:"STORAGE_DRIVER" env-default:"postgres"`
+	}
+
 	PostgreSQL struct {
 		User     string `env:"POSTGRESQL_USER"`
 		Password string `env:"POSTGRESQL_PASSWORD"`
 		Host     string `env:"POSTGRESQL_HOST"`
 		Database string `env:"POSTGRESQL_DATABASE"`
+		// StatementTimeout is the default timeout of a single query.
+		StatementTimeout time.Duration `env:"POSTGRESQL_STATEMENT_TIMEOUT" env-default:"5s"`
 	}
 
 	CatAPI struct {
 		URL string `env:"CAT_API_URL"`
+		// Key is sent as x-api-key, requests without it are heavily rate-limited.
+		// It is excluded from JSON, so it is not logged with the config.
+		Key string `env:"CAT_API_KEY" json:"-"`
+		// QuotaResetFallback is how long an exhausted quota without reported reset time lasts.
+		QuotaResetFallback time.Duration `env:"CAT_API_QUOTA_RESET_FALLBACK" env-default:"1m"`
+		// CacheTTL is how long fetched breeds are served before they are refreshed.
+		CacheTTL time.Duration `env:"CAT_API_CACHE_TTL" env-default:"1h"`
+		// CacheRetryInterval is how long the cache waits before retrying a failed refresh.
+		CacheRetryInterval time.Duration `env:"CAT_API_CACHE_RETRY_INTERVAL" env-default:"30s"`
+		// Timeout limits a single request attempt.
+		Timeout time.Duration `env:"CAT_API_TIMEOUT" env-default:"10s"`
+		// MaxRetries is the number of retries of network errors, 429 and 5xx responses.
+		MaxRetries int `env:"CAT_API_MAX_RETRIES" env-default:"3"`
+		// RetryBaseDelay doubles with every retry up to RetryMaxDelay.
+		RetryBaseDelay time.Duration `env:"CAT_API_RETRY_BASE_DELAY" env-default:"200ms"`
+		RetryMaxDelay  time.Duration `env:"CAT_API_RETRY_MAX_DELAY" env-default:"5s"`
+		// BreakerThreshold consecutive failed requests stop calls for BreakerCooldown.
+		BreakerThreshold int           `env:"CAT_API_BREAKER_THRESHOLD" env-default:"5"`
+		BreakerCooldown  time.Duration `env:"CAT_API_BREAKER_COOLDOWN" env-default:"30s"`
+	}
+
+	Salary struct {
+		// ScheduleInterval is how often scheduled salary changes are applied.
+		ScheduleInterval time.Duration `env:"SALARY_SCHEDULE_INTERVAL" env-default:"1m"`
+	}
+
+	Breeds struct {
+		// SyncInterval is how often breeds are synced from the providers.
+		SyncInterval time.Duration `env:"BREEDS_SYNC_INTERVAL" env-default:"1h"`
+		// Providers are breed sources in order of priority: http, file or static.
+		Providers []string `env:"BREEDS_PROVIDERS" env-default:"http" env-separator:","`
+		// ProvidersMode combines several providers: fallback or merge.
+		ProvidersMode string `env:"BREEDS_PROVIDERS_MODE" env-default:"fallback"`
+		// File is a JSON or YAML list of breeds read by the file provider.
+		File string `env:"BREEDS_FILE"`
+	}
+
+	Payroll struct {
+		// MissionBonus is paid for every mission completed within the month.
+		MissionBonus float64 `env:"PAYROLL_MISSION_BONUS" env-default:"0"`
+		// QueryTimeout overrides the statement timeout for report queries.
+		QueryTimeout time.Duration `env:"PAYROLL_QUERY_TIMEOUT" env-default:"30s"`
 	}
 )
+
+// Validate checks settings that cannot be used as they are.
+func (c *Config) Validate() error {
+	// intervals of periodic jobs, a ticker panics on a non-positive one
+	intervals := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"CAT_API_CACHE_TTL", c.CatAPI.CacheTTL},
+		{"BREEDS_SYNC_INTERVAL", c.Breeds.SyncInterval},
+		{"SALARY_SCHEDULE_INTERVAL", c.Salary.ScheduleInterval},
+		{"IDEMPOTENCY_PURGE_INTERVAL", c.Idempotency.PurgeInterval},
+	}
+	for _, interval := range intervals {
+		if interval.value <= 0 {
+			return fmt.Errorf("%s must be positive, got %s", interval.name, interval.value)
+		}
+	}
+	return nil
+}
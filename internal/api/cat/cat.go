@@ -1,20 +1,36 @@
 package cat
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand/v2"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/Kontentski/develops-today-task/config"
+	"github.com/Kontentski/develops-today-task/pkg/circuitbreaker"
+	"github.com/Kontentski/develops-today-task/pkg/errs"
 	"github.com/Kontentski/develops-today-task/pkg/logging"
 )
 
+var _ Source = (*catAPI)(nil)
+
+// ErrUnavailable is returned without calling TheCatAPI while it is considered down.
+var ErrUnavailable = errs.Unavailable("cat_api_unavailable", "cat breeds service is temporarily unavailable")
+
 // catAPI implements the cat API client
 type catAPI struct {
-	http   *http.Client
-	logger logging.Logger
-	cfg    *config.Config
+	http    *http.Client
+	breaker *circuitbreaker.Breaker
+	logger  logging.Logger
+	cfg     *config.Config
+
+	mu    sync.RWMutex
+	quota *Quota
 }
 
 // Options is used to parameterize catAPI using New
@@ -25,47 +41,225 @@ type Options struct {
 
 // Breed represents a cat breed from TheCatAPI
 type Breed struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Origin      string `json:"origin"`
-	Temperament string `json:"temperament"`
+	ID          string `json:"id" yaml:"id"`
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description" yaml:"description"`
+	Origin      string `json:"origin" yaml:"origin"`
+	Temperament string `json:"temperament" yaml:"temperament"`
+}
+
+// transientError is a failure worth retrying, retryAfter is the delay requested by the server.
+type transientError struct {
+	err        error
+	retryAfter time.Duration
+}
+
+func (e *transientError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transientError) Unwrap() error {
+	return e.err
 }
 
 // New creates a new catAPI instance
 func New(options *Options) *catAPI {
 	return &catAPI{
 		http: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout: options.Config.CatAPI.Timeout,
 		},
-		logger: options.Logger.Named("CatAPI"),
-		cfg:    options.Config,
+		breaker: circuitbreaker.New(options.Config.CatAPI.BreakerThreshold, options.Config.CatAPI.BreakerCooldown),
+		logger:  options.Logger.Named("CatAPI"),
+		cfg:     options.Config,
 	}
 }
 
 // GetBreeds fetches all cat breeds from TheCatAPI
-func (c *catAPI) GetBreeds() ([]Breed, error) {
+func (c *catAPI) GetBreeds(ctx context.Context) ([]Breed, error) {
 	c.logger.Debug("fetching cat breeds")
 
-	// get with context
-	resp, err := c.http.Get(fmt.Sprintf("%s/breeds", c.cfg.CatAPI.URL))
-	if err != nil {
+	var breeds []Breed
+	if err := c.get(ctx, "/breeds", &breeds); err != nil {
 		c.logger.Error("failed to fetch breeds", "err", err)
 		return nil, fmt.Errorf("failed to fetch breeds: %w", err)
 	}
+
+	c.logger.Info("successfully fetched cat breeds", "count", len(breeds))
+	return breeds, nil
+}
+
+// get decodes response of the path into v. Transient failures are retried
+// and reported to the circuit breaker.
+func (c *catAPI) get(ctx context.Context, path string, v interface{}) error {
+	if quota := c.Quota(); quota.Exhausted(time.Now(), c.cfg.CatAPI.QuotaResetFallback) {
+		return ErrQuotaExhausted
+	}
+	if err := c.breaker.Allow(); err != nil {
+		return ErrUnavailable
+	}
+
+	err := c.getWithRetries(ctx, path, v)
+
+	var transient *transientError
+	switch {
+	case err == nil:
+		c.breaker.Success()
+	case ctx.Err() != nil:
+		c.breaker.Ignore()
+	case c.Quota().Exhausted(time.Now(), c.cfg.CatAPI.QuotaResetFallback):
+		// TheCatAPI is up, it only refuses requests until the quota resets
+		c.breaker.Ignore()
+		return fmt.Errorf("%w: %w", ErrQuotaExhausted, err)
+	case errors.As(err, &transient):
+		if c.breaker.Failure() {
+			c.logger.Warn("thecatapi is failing, circuit breaker opened", "cooldown", c.cfg.CatAPI.BreakerCooldown)
+		}
+	default:
+		// TheCatAPI is reachable, the request itself is wrong
+		c.breaker.Success()
+	}
+	return err
+}
+
+// getWithRetries retries transient failures with exponential backoff.
+func (c *catAPI) getWithRetries(ctx context.Context, path string, v interface{}) error {
+	for attempt := 0; ; attempt++ {
+		err := c.getOnce(ctx, path, v)
+
+		var transient *transientError
+		if err == nil || !errors.As(err, &transient) || attempt >= c.cfg.CatAPI.MaxRetries {
+			return err
+		}
+
+		delay := c.backoff(attempt)
+		if transient.retryAfter > 0 {
+			// do not retry earlier than the server asked, nor wait longer than configured
+			if transient.retryAfter > c.cfg.CatAPI.RetryMaxDelay {
+				return err
+			}
+			delay = transient.retryAfter
+		}
+		if c.Quota().Exhausted(time.Now().Add(delay), c.cfg.CatAPI.QuotaResetFallback) {
+			return err
+		}
+
+		c.logger.Warn("retrying thecatapi request", "path", path, "attempt", attempt+1, "delay", delay, "err", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// getOnce makes a single request, network errors, 429 and 5xx responses are transient.
+func (c *catAPI) getOnce(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.cfg.CatAPI.URL+path, nil)
+	if err != nil {
+		return err
+	}
+	if c.cfg.CatAPI.Key != "" {
+		req.Header.Set("x-api-key", c.cfg.CatAPI.Key)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return &transientError{err: err}
+	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		c.logger.Error("thecatapi responded with non-200 status", "status", resp.Status)
-		return nil, fmt.Errorf("thecatapi responded with status: %s", resp.Status)
+	c.updateQuota(resp)
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return &transientError{
+			err:        fmt.Errorf("thecatapi responded with status: %s", resp.Status),
+			retryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return &transientError{err: fmt.Errorf("thecatapi responded with status: %s", resp.Status)}
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("thecatapi responded with status: %s", resp.Status)
 	}
 
-	var breeds []Breed
-	if err := json.NewDecoder(resp.Body).Decode(&breeds); err != nil {
-		c.logger.Error("failed to decode breeds response", "err", err)
-		return nil, fmt.Errorf("failed to decode breeds response: %w", err)
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return &transientError{err: fmt.Errorf("failed to decode response: %w", err)}
 	}
+	return nil
+}
 
-	c.logger.Info("successfully fetched cat breeds", "count", len(breeds))
-	return breeds, nil
+// Quota returns the quota reported by the last response, nil if it is unknown.
+func (c *catAPI) Quota() *Quota {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.quota == nil {
+		return nil
+	}
+	quota := *c.quota
+	return &quota
+}
+
+// updateQuota records the quota reported by the response.
+// Too many requests responses exhaust the quota until Retry-After.
+func (c *catAPI) updateQuota(resp *http.Response) {
+	now := time.Now()
+	quota := parseQuota(resp.Header, now)
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if quota == nil {
+			quota = &Quota{UpdatedAt: now}
+		}
+		quota.Remaining = 0
+		if retryAfter := parseRetryAfter(resp.Header.Get("Retry-After")); retryAfter > 0 {
+			resetAt := now.Add(retryAfter)
+			quota.ResetAt = &resetAt
+		}
+	}
+	if quota == nil {
+		return
+	}
+
+	c.mu.Lock()
+	c.quota = quota
+	c.mu.Unlock()
+
+	switch {
+	case quota.Remaining == 0:
+		c.logger.Warn("thecatapi quota exhausted", "limit", quota.Limit, "resetAt", quota.ResetAt)
+	case quota.low():
+		c.logger.Warn("thecatapi quota is running low", "remaining", quota.Remaining, "limit", quota.Limit, "resetAt", quota.ResetAt)
+	default:
+		c.logger.Debug("thecatapi quota", "remaining", quota.Remaining, "limit", quota.Limit, "resetAt", quota.ResetAt)
+	}
+}
+
+// backoff returns exponential delay of the attempt with equal jitter.
+func (c *catAPI) backoff(attempt int) time.Duration {
+	delay := c.cfg.CatAPI.RetryBaseDelay << attempt
+	if delay <= 0 || delay > c.cfg.CatAPI.RetryMaxDelay {
+		delay = c.cfg.CatAPI.RetryMaxDelay
+	}
+
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return half + rand.N(half)
+}
+
+// parseRetryAfter parses Retry-After header given in seconds or as HTTP date, zero if absent or invalid.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0)
+	}
+	return 0
 }
@@ -0,0 +1,154 @@
+package cat
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Kontentski/develops-today-task/pkg/logging"
+)
+
+// Source provides cat breeds.
+type Source interface {
+	GetBreeds(ctx context.Context) ([]Breed, error)
+}
+
+// quotaSource is a Source with a request quota.
+type quotaSource interface {
+	Quota() *Quota
+}
+
+// Catalog caches breeds of a source. Stale breeds are served while they are
+// refreshed in the background, and the embedded snapshot is served until the
+// source responds successfully for the first time.
+type Catalog struct {
+	source        Source
+	logger        logging.Logger
+	ttl           time.Duration
+	retryInterval time.Duration
+	snapshot      []Breed
+
+	mu        sync.RWMutex
+	breeds    []Breed
+	fetchedAt time.Time
+	failedAt  time.Time
+
+	refreshing atomic.Bool
+	hits       atomic.Uint64
+	misses     atomic.Uint64
+}
+
+// CatalogOptions is used to parameterize Catalog using NewCatalog
+type CatalogOptions struct {
+	Logger logging.Logger
+	// TTL is how long fetched breeds are considered fresh.
+	TTL time.Duration
+	// RetryInterval is how long background refreshes wait after a failed one.
+	RetryInterval time.Duration
+}
+
+// CatalogStats describes the catalog cache.
+type CatalogStats struct {
+	// Hits counts requests served from fetched breeds, Misses from the snapshot.
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+	// FetchedAt is the time of the last successful fetch, nil if there was none.
+	FetchedAt *time.Time `json:"fetchedAt,omitempty"`
+	Stale     bool       `json:"stale"`
+}
+
+// NewCatalog creates a new Catalog of the source
+func NewCatalog(source Source, options *CatalogOptions) *Catalog {
+	return &Catalog{
+		source:        source,
+		logger:        options.Logger.Named("BreedCatalog"),
+		ttl:           options.TTL,
+		retryInterval: options.RetryInterval,
+		snapshot:      Snapshot(),
+	}
+}
+
+// GetBreeds returns cached breeds without waiting for the source.
+func (c *Catalog) GetBreeds(ctx context.Context) ([]Breed, error) {
+	c.mu.RLock()
+	breeds, fetchedAt, failedAt := c.breeds, c.fetchedAt, c.failedAt
+	c.mu.RUnlock()
+
+	stale := breeds == nil || time.Since(fetchedAt) >= c.ttl
+	if stale && time.Since(failedAt) >= c.retryInterval {
+		c.refreshAsync()
+	}
+
+	if breeds == nil {
+		c.misses.Add(1)
+		return slices.Clone(c.snapshot), nil
+	}
+	c.hits.Add(1)
+	return slices.Clone(breeds), nil
+}
+
+// Refresh fetches breeds from the source, cached breeds are kept if it fails.
+func (c *Catalog) Refresh(ctx context.Context) error {
+	breeds, err := c.source.GetBreeds(ctx)
+	if err == nil && len(breeds) == 0 {
+		err = errors.New("source returned no breeds")
+	}
+	if err != nil {
+		c.mu.Lock()
+		c.failedAt = time.Now()
+		c.mu.Unlock()
+
+		c.logger.Warn("failed to refresh breeds, serving cached breeds", "err", err)
+		return err
+	}
+
+	c.mu.Lock()
+	c.breeds = breeds
+	c.fetchedAt = time.Now()
+	c.failedAt = time.Time{}
+	c.mu.Unlock()
+
+	c.logger.Info("breeds refreshed", "count", len(breeds), "hits", c.hits.Load(), "misses", c.misses.Load())
+	return nil
+}
+
+// Stats returns cache counters and freshness.
+func (c *Catalog) Stats() CatalogStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	stats := CatalogStats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+		Stale:  c.breeds == nil || time.Since(c.fetchedAt) >= c.ttl,
+	}
+	if c.breeds != nil {
+		fetchedAt := c.fetchedAt
+		stats.FetchedAt = &fetchedAt
+	}
+	return stats
+}
+
+// Quota returns the request quota of the source, nil if it is unknown or the source has none.
+func (c *Catalog) Quota() *Quota {
+	if source, ok := c.source.(quotaSource); ok {
+		return source.Quota()
+	}
+	return nil
+}
+
+// refreshAsync starts a background refresh unless one is already running.
+// It is not bound to the caller context, so it outlives the request.
+func (c *Catalog) refreshAsync() {
+	if !c.refreshing.CompareAndSwap(false, true) {
+		return
+	}
+
+	go func() {
+		defer c.refreshing.Store(false)
+		_ = c.Refresh(context.Background())
+	}()
+}
@@ -0,0 +1,71 @@
+package cat
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Kontentski/develops-today-task/pkg/errs"
+)
+
+// ErrQuotaExhausted is returned without calling TheCatAPI until the exhausted quota resets.
+var ErrQuotaExhausted = errs.Unavailable("cat_api_quota_exhausted", "cat breeds service quota is exhausted")
+
+// quotaWarnRatio is the share of the quota left below which every response is logged as a warning.
+const quotaWarnRatio = 0.1
+
+// Quota is the TheCatAPI request quota reported by the last response.
+type Quota struct {
+	// Limit is zero if TheCatAPI did not report it.
+	Limit     int `json:"limit,omitempty"`
+	Remaining int `json:"remaining"`
+	// ResetAt is when the quota is restored, nil if TheCatAPI did not report it.
+	ResetAt   *time.Time `json:"resetAt,omitempty"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+// Exhausted reports whether no requests are left until the quota resets.
+// A quota without reset time is considered restored after fallback.
+func (q *Quota) Exhausted(now time.Time, fallback time.Duration) bool {
+	if q == nil || q.Remaining > 0 {
+		return false
+	}
+	if q.ResetAt != nil {
+		return now.Before(*q.ResetAt)
+	}
+	return now.Before(q.UpdatedAt.Add(fallback))
+}
+
+// low reports whether the quota is nearly exhausted.
+func (q *Quota) low() bool {
+	if q.Limit > 0 {
+		return float64(q.Remaining) < float64(q.Limit)*quotaWarnRatio
+	}
+	return q.Remaining == 0
+}
+
+// parseQuota parses X-RateLimit-* headers, nil if the response has none.
+// Reset is given either in seconds until reset or as a unix timestamp.
+func parseQuota(header http.Header, now time.Time) *Quota {
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return nil
+	}
+
+	quota := &Quota{
+		Remaining: max(remaining, 0),
+		UpdatedAt: now,
+	}
+	if limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit")); err == nil {
+		quota.Limit = limit
+	}
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil && reset > 0 {
+		resetAt := now.Add(time.Duration(reset) * time.Second)
+		// values larger than a year of seconds are timestamps
+		if reset > int64((365 * 24 * time.Hour).Seconds()) {
+			resetAt = time.Unix(reset, 0)
+		}
+		quota.ResetAt = &resetAt
+	}
+	return quota
+}
@@ -0,0 +1,116 @@
+package cat
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseQuota(t *testing.T) {
+	now := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+	header := func(kv ...string) http.Header {
+		h := http.Header{}
+		for i := 0; i < len(kv); i += 2 {
+			h.Set(kv[i], kv[i+1])
+		}
+		return h
+	}
+	at := func(t time.Time) *time.Time { return &t }
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   *Quota
+	}{
+		{
+			name:   "no headers",
+			header: header(),
+		},
+		{
+			name:   "invalid remaining",
+			header: header("X-RateLimit-Remaining", "many", "X-RateLimit-Limit", "100"),
+		},
+		{
+			name:   "remaining only",
+			header: header("X-RateLimit-Remaining", "42"),
+			want:   &Quota{Remaining: 42, UpdatedAt: now},
+		},
+		{
+			name:   "negative remaining",
+			header: header("X-RateLimit-Remaining", "-1"),
+			want:   &Quota{Remaining: 0, UpdatedAt: now},
+		},
+		{
+			name: "reset in seconds",
+			header: header(
+				"X-RateLimit-Remaining", "5",
+				"X-RateLimit-Limit", "100",
+				"X-RateLimit-Reset", "60",
+			),
+			want: &Quota{Limit: 100, Remaining: 5, ResetAt: at(now.Add(time.Minute)), UpdatedAt: now},
+		},
+		{
+			name: "reset as timestamp",
+			header: header(
+				"X-RateLimit-Remaining", "0",
+				"X-RateLimit-Reset", "1790000000",
+			),
+			want: &Quota{Remaining: 0, ResetAt: at(time.Unix(1790000000, 0)), UpdatedAt: now},
+		},
+		{
+			name: "invalid limit and reset",
+			header: header(
+				"X-RateLimit-Remaining", "7",
+				"X-RateLimit-Limit", "lots",
+				"X-RateLimit-Reset", "0",
+			),
+			want: &Quota{Remaining: 7, UpdatedAt: now},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseQuota(tt.header, now)
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("parseQuota() = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("parseQuota() = nil, want %+v", tt.want)
+			}
+			if got.Limit != tt.want.Limit || got.Remaining != tt.want.Remaining || !got.UpdatedAt.Equal(tt.want.UpdatedAt) {
+				t.Fatalf("parseQuota() = %+v, want %+v", got, tt.want)
+			}
+			if (got.ResetAt == nil) != (tt.want.ResetAt == nil) ||
+				(got.ResetAt != nil && !got.ResetAt.Equal(*tt.want.ResetAt)) {
+				t.Fatalf("parseQuota().ResetAt = %v, want %v", got.ResetAt, tt.want.ResetAt)
+			}
+		})
+	}
+}
+
+func TestQuotaExhausted(t *testing.T) {
+	now := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+	later := now.Add(time.Minute)
+
+	tests := []struct {
+		name  string
+		quota *Quota
+		want  bool
+	}{
+		{name: "unknown", quota: nil, want: false},
+		{name: "requests left", quota: &Quota{Remaining: 1, UpdatedAt: now}, want: false},
+		{name: "before reset", quota: &Quota{ResetAt: &later, UpdatedAt: now}, want: true},
+		{name: "after reset", quota: &Quota{ResetAt: &now, UpdatedAt: now}, want: false},
+		{name: "no reset within fallback", quota: &Quota{UpdatedAt: now.Add(-time.Second)}, want: true},
+		{name: "no reset after fallback", quota: &Quota{UpdatedAt: now.Add(-time.Hour)}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.quota.Exhausted(now, time.Minute); got != tt.want {
+				t.Fatalf("Exhausted() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package cat
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Kontentski/develops-today-task/pkg/logging"
+)
+
+// snapshotJSON is a copy of TheCatAPI breeds bundled with the binary.
+//
+//go:embed breeds.json
+var snapshotJSON []byte
+
+// Snapshot returns breeds of TheCatAPI bundled with the binary.
+func Snapshot() []Breed {
+	var breeds []Breed
+	if err := json.Unmarshal(snapshotJSON, &breeds); err != nil {
+		panic("cat: invalid breeds snapshot: " + err.Error())
+	}
+	return breeds
+}
+
+var (
+	_ Source = (*StaticSource)(nil)
+	_ Source = (*FileSource)(nil)
+	_ Source = (*CompositeSource)(nil)
+)
+
+// StaticSource provides a fixed list of breeds.
+type StaticSource struct {
+	breeds []Breed
+}
+
+// NewStaticSource creates a source of passed breeds.
+func NewStaticSource(breeds []Breed) *StaticSource {
+	return &StaticSource{breeds: slices.Clone(breeds)}
+}
+
+func (s *StaticSource) GetBreeds(ctx context.Context) ([]Breed, error) {
+	return slices.Clone(s.breeds), nil
+}
+
+// FileSource reads breeds from a JSON or YAML file on every call,
+// so changes of the file are picked up without restart.
+type FileSource struct {
+	path string
+}
+
+// NewFileSource creates a source of the file, its format is chosen by extension: .json, .yaml or .yml.
+func NewFileSource(path string) (*FileSource, error) {
+	switch filepath.Ext(path) {
+	case ".json", ".yaml", ".yml":
+		return &FileSource{path: path}, nil
+	default:
+		return nil, fmt.Errorf("unsupported breeds file format %q", path)
+	}
+}
+
+func (s *FileSource) GetBreeds(ctx context.Context) ([]Breed, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read breeds file: %w", err)
+	}
+
+	var breeds []Breed
+	if filepath.Ext(s.path) == ".json" {
+		err = json.Unmarshal(data, &breeds)
+	} else {
+		err = yaml.Unmarshal(data, &breeds)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse breeds file %s: %w", s.path, err)
+	}
+	return breeds, nil
+}
+
+// CompositeMode defines how CompositeSource combines its sources.
+type CompositeMode string
+
+const (
+	// CompositeFallback returns breeds of the first source that succeeds.
+	CompositeFallback CompositeMode = "fallback"
+	// CompositeMerge returns breeds of all sources that succeed,
+	// breeds of earlier sources win over later ones with the same ID.
+	CompositeMerge CompositeMode = "merge"
+)
+
+// CompositeSource combines breeds of several sources in order.
+type CompositeSource struct {
+	mode    CompositeMode
+	sources []Source
+	logger  logging.Logger
+}
+
+// CompositeOptions is used to parameterize CompositeSource using NewCompositeSource
+type CompositeOptions struct {
+	Mode   CompositeMode
+	Logger logging.Logger
+}
+
+// NewCompositeSource creates a source combining passed sources.
+func NewCompositeSource(options *CompositeOptions, sources ...Source) (*CompositeSource, error) {
+	if options.Mode != CompositeFallback && options.Mode != CompositeMerge {
+		return nil, fmt.Errorf("unknown composite mode %q", options.Mode)
+	}
+	if len(sources) == 0 {
+		return nil, errors.New("composite source requires at least one source")
+	}
+
+	return &CompositeSource{
+		mode:    options.Mode,
+		sources: sources,
+		logger:  options.Logger.Named("CompositeBreedSource"),
+	}, nil
+}
+
+// GetBreeds fails only if all sources fail or return no breeds.
+func (s *CompositeSource) GetBreeds(ctx context.Context) ([]Breed, error) {
+	var (
+		merged []Breed
+		seen   = map[string]bool{}
+		errs   []error
+	)
+
+	for i, source := range s.sources {
+		breeds, err := source.GetBreeds(ctx)
+		if err == nil && len(breeds) == 0 {
+			err = errors.New("source returned no breeds")
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			s.logger.Warn("breed source failed", "source", i, "err", err)
+			errs = append(errs, fmt.Errorf("source %d: %w", i, err))
+			continue
+		}
+
+		if s.mode == CompositeFallback {
+			return breeds, nil
+		}
+		for _, breed := range breeds {
+			if !seen[breed.ID] {
+				seen[breed.ID] = true
+				merged = append(merged, breed)
+			}
+		}
+	}
+
+	if len(merged) == 0 {
+		return nil, errors.Join(errs...)
+	}
+	return merged, nil
+}
+
+// Quota returns the quota of the first source reporting one.
+func (s *CompositeSource) Quota() *Quota {
+	for _, source := range s.sources {
+		if source, ok := source.(quotaSource); ok {
+			if quota := source.Quota(); quota != nil {
+				return quota
+			}
+		}
+	}
+	return nil
+}
@@ -1,66 +1,55 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/Kontentski/develops-today-task/config"
+	"github.com/Kontentski/develops-today-task/migrations"
 	"github.com/Kontentski/develops-today-task/pkg/httpserver"
 	"github.com/Kontentski/develops-today-task/pkg/logging"
+	"github.com/Kontentski/develops-today-task/pkg/migrate"
 	"github.com/Kontentski/develops-today-task/pkg/postgresql"
 	"github.com/gin-gonic/gin"
 
 	"github.com/Kontentski/develops-today-task/internal/api/cat"
 	httpController "github.com/Kontentski/develops-today-task/internal/controller/http"
-	"github.com/Kontentski/develops-today-task/internal/entity"
 	"github.com/Kontentski/develops-today-task/internal/service"
 	"github.com/Kontentski/develops-today-task/internal/storage"
+	"github.com/Kontentski/develops-today-task/internal/storage/memory"
 )
 
 // Run - initializes and runs application.
 func Run(cfg *config.Config) {
 	logger := logging.NewZapLogger(cfg.Log.Level)
 
-	postgresql, err := postgresql.NewPostgreSQLGorm(postgresql.Config{
-		User:     cfg.PostgreSQL.User,
-		Password: cfg.PostgreSQL.Password,
-		Host:     cfg.PostgreSQL.Host,
-		Database: cfg.PostgreSQL.Database,
-	})
+	storages, err := newStorages(context.Background(), cfg, logger)
 	if err != nil {
-		log.Fatal(fmt.Errorf("failed to init repository: %w", err))
+		log.Fatal(err)
 	}
 
-	// create UUID extension.
-	err = postgresql.DB.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp"`).Error
+	breedSource, err := newBreedSource(cfg, logger)
 	if err != nil {
-		log.Fatal(fmt.Errorf("failed to create uuid-ossp extension: %w", err))
+		log.Fatal(err)
 	}
 
-	err = postgresql.DB.AutoMigrate(
-		&entity.SpyCat{},
-		&entity.Mission{},
-		&entity.Target{},
+	breedCatalog := cat.NewCatalog(
+		breedSource,
+		&cat.CatalogOptions{
+			Logger:        logger,
+			TTL:           cfg.CatAPI.CacheTTL,
+			RetryInterval: cfg.CatAPI.CacheRetryInterval,
+		},
 	)
-	if err != nil {
-		log.Fatal(fmt.Errorf("automigration failed: %w", err))
-	}
-
-	storages := service.Storages{
-		SpyCat:  storage.NewSpyCatStorage(postgresql),
-		Mission: storage.NewMissionStorage(postgresql),
-		Target:  storage.NewTargetStorage(postgresql),
-	}
 
 	apis := service.APIs{
-		CatAPI: cat.New(&cat.Options{
-			Logger: logger,
-			Config: cfg,
-		}),
+		CatAPI: breedCatalog,
 	}
 
 	serviceOptions := service.Options{
@@ -70,20 +59,53 @@ func Run(cfg *config.Config) {
 		Logger:   logger,
 	}
 
-	services := service.Services{
-		SpyCat:  service.NewSpyCatService(serviceOptions, storages.SpyCat),
-		Mission: service.NewMissionService(serviceOptions, storages.Mission),
-		Target:  service.NewTargetService(serviceOptions, storages.Target),
+	services := service.NewService(serviceOptions)
+
+	// background jobs
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+
+	// the breed registry keeps serving stored breeds when a sync fails, until TheCatAPI
+	// responds the catalog returns its bundled snapshot, so a fresh database is seeded offline
+	syncBreeds := func(ctx context.Context) {
+		if _, err := services.Breed.SyncBreeds(ctx); err != nil {
+			logger.Error("app - Run - SyncBreeds", "err", err)
+		}
 	}
 
+	// failed refreshes are logged by the catalog, which keeps serving cached breeds,
+	// the first sync waits for the first refresh, so it stores fetched breeds if it succeeds
+	go func() {
+		_ = breedCatalog.Refresh(jobsCtx)
+		syncBreeds(jobsCtx)
+	}()
+	go runPeriodically(jobsCtx, cfg.CatAPI.CacheTTL, func(ctx context.Context) {
+		_ = breedCatalog.Refresh(ctx)
+	})
+	go runPeriodically(jobsCtx, cfg.Breeds.SyncInterval, syncBreeds)
+
+	go runPeriodically(jobsCtx, cfg.Salary.ScheduleInterval, func(ctx context.Context) {
+		if _, err := services.SpyCat.ApplyScheduledSalaryChanges(ctx); err != nil {
+			logger.Error("app - Run - ApplyScheduledSalaryChanges", "err", err)
+		}
+	})
+
+	go runPeriodically(jobsCtx, cfg.Idempotency.PurgeInterval, func(ctx context.Context) {
+		if _, err := services.Idempotency.PurgeExpiredIdempotencyKeys(ctx); err != nil {
+			logger.Error("app - Run - PurgeExpiredIdempotencyKeys", "err", err)
+		}
+	})
+
 	httpHandler := gin.New()
 
-	httpController.New(httpController.Options{
+	err = httpController.New(httpController.Options{
 		Handler:  httpHandler,
 		Services: services,
 		Logger:   logger,
 		Config:   cfg,
 	})
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	httpServer := httpserver.New(
 		httpHandler,
@@ -104,8 +126,117 @@ func Run(cfg *config.Config) {
 		logger.Error("app - Run - httpServer.Notify", "err", err)
 	}
 
+	stopJobs()
+
 	err = httpServer.Shutdown()
 	if err != nil {
 		logger.Error("app - Run - httpServer.Shutdown", "err", err)
 	}
 }
+
+// newStorages - creates storages of the configured driver.
+func newStorages(ctx context.Context, cfg *config.Config, logger logging.Logger) (service.Storages, error) {
+	switch cfg.Storage.Driver {
+	case "postgres":
+	case "memory":
+		return memory.NewStorages(memory.NewStore()), nil
+	default:
+		return service.Storages{}, fmt.Errorf("unknown storage driver %q", cfg.Storage.Driver)
+	}
+
+	postgresql, err := newPostgreSQL(cfg)
+	if err != nil {
+		return service.Storages{}, err
+	}
+
+	migrator, err := newMigrator(postgresql)
+	if err != nil {
+		return service.Storages{}, err
+	}
+
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		return service.Storages{}, fmt.Errorf("migration failed: %w", err)
+	}
+	for _, migration := range applied {
+		logger.Info("app - Run - applied migration", "migration", migration.String())
+	}
+
+	return storage.NewStorages(postgresql), nil
+}
+
+// newBreedSource - creates breed source of the configured providers.
+func newBreedSource(cfg *config.Config, logger logging.Logger) (cat.Source, error) {
+	sources := make([]cat.Source, 0, len(cfg.Breeds.Providers))
+	for _, provider := range cfg.Breeds.Providers {
+		switch strings.TrimSpace(provider) {
+		case "http":
+			sources = append(sources, cat.New(&cat.Options{
+				Logger: logger,
+				Config: cfg,
+			}))
+		case "file":
+			source, err := cat.NewFileSource(cfg.Breeds.File)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, source)
+		case "static":
+			sources = append(sources, cat.NewStaticSource(cat.Snapshot()))
+		default:
+			return nil, fmt.Errorf("unknown breeds provider %q", provider)
+		}
+	}
+
+	if len(sources) == 1 {
+		return sources[0], nil
+	}
+	return cat.NewCompositeSource(&cat.CompositeOptions{
+		Mode:   cat.CompositeMode(cfg.Breeds.ProvidersMode),
+		Logger: logger,
+	}, sources...)
+}
+
+// newPostgreSQL - connects to the configured database.
+func newPostgreSQL(cfg *config.Config) (*postgresql.PostgreSQLGorm, error) {
+	postgresql, err := postgresql.NewPostgreSQLGorm(postgresql.Config{
+		User:             cfg.PostgreSQL.User,
+		Password:         cfg.PostgreSQL.Password,
+		Host:             cfg.PostgreSQL.Host,
+		Database:         cfg.PostgreSQL.Database,
+		StatementTimeout: cfg.PostgreSQL.StatementTimeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to init repository: %w", err)
+	}
+	return postgresql, nil
+}
+
+// newMigrator - creates migrator of embedded migrations.
+func newMigrator(postgresql *postgresql.PostgreSQLGorm) (*migrate.Migrator, error) {
+	migrations, err := migrate.Load(migrations.FS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	db, err := postgresql.DB.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database handle: %w", err)
+	}
+	return migrate.New(db, migrations), nil
+}
+
+// runPeriodically - calls fn every interval until ctx is done.
+func runPeriodically(ctx context.Context, interval time.Duration, fn func(ctx context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			fn(ctx)
+		}
+	}
+}
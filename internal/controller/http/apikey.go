@@ -0,0 +1,87 @@
+package httpcontroller
+
+import (
+	"time"
+
+	"github.com/Kontentski/develops-today-task/internal/entity"
+	"github.com/Kontentski/develops-today-task/internal/service"
+	"github.com/Kontentski/develops-today-task/pkg/errs"
+	"github.com/gin-gonic/gin"
+)
+
+type apiKeyRoutes struct {
+	routerContext
+}
+
+func newAPIKeyRoutes(options RouterOptions) {
+	r := &apiKeyRoutes{
+		routerContext{
+			services: options.Services,
+			logger:   options.Logger.Named("apiKeyRoutes"),
+			cfg:      options.Config,
+		},
+	}
+
+	// keys are managed only by admins, never by other keys
+	p := options.Handler.Group("/api-keys", options.RateLimits.middleware("api-keys"))
+	{
+		p.POST("/", authorize(noScope, rolesAdmin...), errorHandler(options, r.createAPIKey))
+		p.GET("/", authorize(noScope, rolesAdmin...), errorHandler(options, r.listAPIKeys))
+		p.DELETE("/:id", authorize(noScope, rolesAdmin...), errorHandler(options, r.revokeAPIKey))
+	}
+}
+
+type createAPIKeyRequest struct {
+	Name      string         `json:"name" binding:"required"`
+	Scopes    []entity.Scope `json:"scopes" binding:"required,min=1"`
+	ExpiresAt *time.Time     `json:"expiresAt"`
+}
+
+func (r *apiKeyRoutes) createAPIKey(c *gin.Context) (interface{}, *httpErr) {
+	var req createAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return nil, &httpErr{Type: httpErrTypeClient, Message: "invalid request body", Details: err}
+	}
+
+	opts := service.CreateAPIKeyOptions{
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	}
+
+	key, err := r.services.APIKey.CreateAPIKey(c, opts)
+	if err != nil {
+		if errs.IsExpected(err) {
+			return nil, newClientErr(err)
+		}
+		return nil, &httpErr{Type: httpErrTypeServer, Message: "failed to create api key", Details: err}
+	}
+
+	return key, nil
+}
+
+func (r *apiKeyRoutes) listAPIKeys(c *gin.Context) (interface{}, *httpErr) {
+	keys, err := r.services.APIKey.ListAPIKeys(c)
+	if err != nil {
+		if errs.IsExpected(err) {
+			return nil, newClientErr(err)
+		}
+		return nil, &httpErr{Type: httpErrTypeServer, Message: "failed to list api keys", Details: err}
+	}
+
+	return keys, nil
+}
+
+func (r *apiKeyRoutes) revokeAPIKey(c *gin.Context) (interface{}, *httpErr) {
+	id := c.Param("id")
+
+	key, err := r.services.APIKey.RevokeAPIKey(c, id)
+	if err != nil {
+		if errs.IsExpected(err) {
+			return nil, newClientErr(err)
+		}
+		return nil, &httpErr{Type: httpErrTypeServer, Message: "failed to revoke api key", Details: err}
+	}
+
+	return key, nil
+}
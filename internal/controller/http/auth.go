@@ -0,0 +1,182 @@
+package httpcontroller
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	// third party
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/Kontentski/develops-today-task/config"
+	"github.com/Kontentski/develops-today-task/internal/entity"
+	"github.com/Kontentski/develops-today-task/internal/service"
+	"github.com/Kontentski/develops-today-task/pkg/errs"
+)
+
+// minJWTSecretLength is the minimum length of HS256 secrets, shorter ones can be guessed.
+const minJWTSecretLength = 32
+
+// principalContextKey is the gin context key of the authenticated principal.
+const principalContextKey = "Principal"
+
+// authClaims are claims of access tokens, the subject is the principal ID.
+type authClaims struct {
+	Role entity.Role `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// noScope is the scope of routes not available to API keys.
+const noScope entity.Scope = ""
+
+// authenticator verifies access tokens signed with HS256 or RS256 and API keys of machine clients.
+type authenticator struct {
+	secret    []byte
+	publicKey *rsa.PublicKey
+	parser    *jwt.Parser
+	apiKeys   service.APIKeyService
+}
+
+// newAuthenticator creates an authenticator of the configured keys, at least one key is required.
+func newAuthenticator(cfg config.Auth, apiKeys service.APIKeyService) (*authenticator, error) {
+	a := &authenticator{apiKeys: apiKeys}
+	var methods []string
+
+	if cfg.JWTSecret != "" {
+		if len(cfg.JWTSecret) < minJWTSecretLength {
+			return nil, fmt.Errorf("jwt secret must be at least %d bytes long", minJWTSecretLength)
+		}
+		a.secret = []byte(cfg.JWTSecret)
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if cfg.JWTPublicKeyFile != "" {
+		data, err := os.ReadFile(cfg.JWTPublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read jwt public key: %w", err)
+		}
+		a.publicKey, err = jwt.ParseRSAPublicKeyFromPEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse jwt public key: %w", err)
+		}
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	if len(methods) == 0 {
+		return nil, errors.New("jwt secret or public key is required")
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+	}
+	if cfg.JWTIssuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.JWTIssuer))
+	}
+	if cfg.JWTAudience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.JWTAudience))
+	}
+	a.parser = jwt.NewParser(opts...)
+	return a, nil
+}
+
+// principal verifies the token and returns its principal.
+func (a *authenticator) principal(token string) (*entity.Principal, error) {
+	var claims authClaims
+	_, err := a.parser.ParseWithClaims(token, &claims, func(t *jwt.Token) (interface{}, error) {
+		// valid methods are checked by the parser
+		if t.Method.Alg() == jwt.SigningMethodRS256.Alg() {
+			return a.publicKey, nil
+		}
+		return a.secret, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Subject == "" {
+		return nil, errors.New("token has no subject")
+	}
+	if !claims.Role.IsValid() {
+		return nil, fmt.Errorf("token has unknown role %q", claims.Role)
+	}
+	return &entity.Principal{ID: claims.Subject, Kind: entity.PrincipalUser, Role: claims.Role}, nil
+}
+
+// authenticate - used to require a bearer token or an API key and put its principal into the request context.
+func (a *authenticator) authenticate(c *gin.Context) {
+	scheme, credentials, _ := strings.Cut(c.GetHeader("Authorization"), " ")
+
+	var principal *entity.Principal
+	switch {
+	case credentials == "":
+		abortUnauthorized(c, "unauthorized", "missing bearer token or api key")
+		return
+
+	case strings.EqualFold(scheme, "Bearer"):
+		var err error
+		principal, err = a.principal(credentials)
+		if err != nil {
+			abortUnauthorized(c, "invalid_token", "invalid token: "+err.Error())
+			return
+		}
+
+	case strings.EqualFold(scheme, "ApiKey"):
+		var err error
+		principal, err = a.apiKeys.AuthenticateAPIKey(c, credentials)
+		if err != nil {
+			if errs.IsExpected(err) {
+				abortUnauthorized(c, errs.CodeOf(err), err.Error())
+				return
+			}
+			abortWithProblem(c, newProblem(c, http.StatusInternalServerError, "", "failed to authenticate api key"))
+			return
+		}
+
+	default:
+		abortUnauthorized(c, "unauthorized", "unsupported authorization scheme")
+		return
+	}
+
+	c.Set(principalContextKey, principal)
+	c.Request = c.Request.WithContext(service.WithPrincipal(c.Request.Context(), principal))
+	c.Next()
+}
+
+// authorize - used to allow the route to users of passed roles and to API keys with the scope.
+func authorize(scope entity.Scope, roles ...entity.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := principalOf(c)
+
+		allowed := false
+		switch {
+		case principal == nil:
+		case principal.Kind == entity.PrincipalAPIKey:
+			allowed = scope != noScope && slices.Contains(principal.Scopes, scope)
+		default:
+			allowed = slices.Contains(roles, principal.Role)
+		}
+
+		if !allowed {
+			abortWithProblem(c, newProblem(c, http.StatusForbidden, "forbidden", "this operation is not allowed for your role or api key scopes"))
+			return
+		}
+		c.Next()
+	}
+}
+
+// principalOf returns the authenticated principal of the request, nil if there is none.
+func principalOf(c *gin.Context) *entity.Principal {
+	principal, _ := c.Get(principalContextKey)
+	p, _ := principal.(*entity.Principal)
+	return p
+}
+
+// abortUnauthorized writes 401 problem asking for credentials.
+func abortUnauthorized(c *gin.Context, code, detail string) {
+	c.Header("WWW-Authenticate", "Bearer, ApiKey")
+	abortWithProblem(c, newProblem(c, http.StatusUnauthorized, code, detail))
+}
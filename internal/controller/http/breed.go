@@ -0,0 +1,127 @@
+package httpcontroller
+
+import (
+	"github.com/Kontentski/develops-today-task/internal/entity"
+	"github.com/Kontentski/develops-today-task/internal/service"
+	"github.com/Kontentski/develops-today-task/pkg/errs"
+	"github.com/gin-gonic/gin"
+)
+
+type breedRoutes struct {
+	routerContext
+}
+
+func newBreedRoutes(options RouterOptions) {
+	r := &breedRoutes{
+		routerContext{
+			services: options.Services,
+			logger:   options.Logger.Named("breedRoutes"),
+			cfg:      options.Config,
+		},
+	}
+
+	p := options.Handler.Group("/breeds", options.RateLimits.middleware("breeds"))
+	{
+		p.POST("/", authorize(entity.ScopeBreedsWrite, rolesAdmin...), idempotent(options), errorHandler(options, r.createBreed))
+		p.GET("/", authorize(entity.ScopeBreedsRead, rolesAll...), errorHandler(options, r.listBreeds))
+		p.GET("/:id/stats", authorize(entity.ScopeBreedsRead, rolesStaff...), errorHandler(options, r.getBreedStats))
+		p.GET("/sync", authorize(entity.ScopeBreedsRead, rolesAdmin...), errorHandler(options, r.getLastBreedSync))
+		p.GET("/quota", authorize(entity.ScopeBreedsRead, rolesAdmin...), errorHandler(options, r.getCatAPIQuota))
+	}
+}
+
+type createBreedRequest struct {
+	ID          string `json:"id"`
+	Name        string `json:"name" binding:"required"`
+	Origin      string `json:"origin"`
+	Temperament string `json:"temperament"`
+	Description string `json:"description"`
+}
+
+func (r *breedRoutes) createBreed(c *gin.Context) (interface{}, *httpErr) {
+	var req createBreedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return nil, &httpErr{Type: httpErrTypeClient, Message: "invalid request body", Details: err}
+	}
+
+	opts := service.CreateBreedOptions{
+		ID:          req.ID,
+		Name:        req.Name,
+		Origin:      req.Origin,
+		Temperament: req.Temperament,
+		Description: req.Description,
+	}
+
+	breed, err := r.services.Breed.CreateBreed(c, opts)
+	if err != nil {
+		if errs.IsExpected(err) {
+			return nil, newClientErr(err)
+		}
+		return nil, &httpErr{Type: httpErrTypeServer, Message: "failed to create breed", Details: err}
+	}
+
+	return breed, nil
+}
+
+func (r *breedRoutes) getLastBreedSync(c *gin.Context) (interface{}, *httpErr) {
+	sync, err := r.services.Breed.GetLastBreedSync(c)
+	if err != nil {
+		if errs.IsExpected(err) {
+			return nil, newClientErr(err)
+		}
+		return nil, &httpErr{Type: httpErrTypeServer, Message: "failed to get last breed sync", Details: err}
+	}
+
+	return sync, nil
+}
+
+type listBreedsRequest struct {
+	Name   string `form:"name"`
+	Origin string `form:"origin"`
+}
+
+func (r *breedRoutes) listBreeds(c *gin.Context) (interface{}, *httpErr) {
+	var req listBreedsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		return nil, &httpErr{Type: httpErrTypeClient, Message: "invalid query parameters", Details: err}
+	}
+
+	breeds, err := r.services.Breed.ListBreeds(c, service.ListBreedsOptions{
+		Name:   req.Name,
+		Origin: req.Origin,
+	})
+	if err != nil {
+		if errs.IsExpected(err) {
+			return nil, newClientErr(err)
+		}
+		return nil, &httpErr{Type: httpErrTypeServer, Message: "failed to list breeds", Details: err}
+	}
+
+	return breeds, nil
+}
+
+func (r *breedRoutes) getBreedStats(c *gin.Context) (interface{}, *httpErr) {
+	id := c.Param("id")
+
+	stats, err := r.services.Breed.GetBreedStats(c, id)
+	if err != nil {
+		if errs.IsExpected(err) {
+			return nil, newClientErr(err)
+		}
+		return nil, &httpErr{Type: httpErrTypeServer, Message: "failed to get breed stats", Details: err}
+	}
+
+	return stats, nil
+}
+
+func (r *breedRoutes) getCatAPIQuota(c *gin.Context) (interface{}, *httpErr) {
+	quota, err := r.services.Breed.GetCatAPIQuota(c)
+	if err != nil {
+		if errs.IsExpected(err) {
+			return nil, newClientErr(err)
+		}
+		return nil, &httpErr{Type: httpErrTypeServer, Message: "failed to get thecatapi quota", Details: err}
+	}
+
+	return quota, nil
+}
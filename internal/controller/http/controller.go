@@ -2,6 +2,8 @@ package httpcontroller
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"runtime/debug"
@@ -9,8 +11,11 @@ import (
 	// third party
 	"github.com/DataDog/gostackparse"
 	"github.com/Kontentski/develops-today-task/config"
+	"github.com/Kontentski/develops-today-task/internal/entity"
 	"github.com/Kontentski/develops-today-task/internal/service"
+	"github.com/Kontentski/develops-today-task/pkg/errs"
 	"github.com/Kontentski/develops-today-task/pkg/logging"
+	"github.com/Kontentski/develops-today-task/pkg/postgresql"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -24,10 +29,11 @@ type routerContext struct {
 
 // RouterOptions provides shared options for all routers.
 type RouterOptions struct {
-	Handler  *gin.RouterGroup
-	Services service.Services
-	Logger   logging.Logger
-	Config   *config.Config
+	Handler    *gin.RouterGroup
+	Services   service.Services
+	Logger     logging.Logger
+	Config     *config.Config
+	RateLimits *rateLimits
 }
 
 // Options is used to parameterize http controller via New.
@@ -38,16 +44,42 @@ type Options struct {
 	Config   *config.Config
 }
 
+// Roles allowed on routes.
+var (
+	rolesAdmin = []entity.Role{entity.RoleAdmin}
+	rolesStaff = []entity.Role{entity.RoleAdmin, entity.RoleHandler}
+	rolesAll   = []entity.Role{entity.RoleAdmin, entity.RoleHandler, entity.RoleAgent}
+)
+
 // New is used to create new http controller.
-func New(options Options) {
+func New(options Options) error {
+	auth, err := newAuthenticator(options.Config.Auth, options.Services.APIKey)
+	if err != nil {
+		return err
+	}
+	limits, err := newRateLimits(options.Config.RateLimit)
+	if err != nil {
+		return err
+	}
+
 	// options
+	// client IPs limit requests, so forwarded IPs are accepted only from configured proxies
+	if err := options.Handler.SetTrustedProxies(options.Config.HTTP.TrustedProxies); err != nil {
+		return fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+	// use request context in handlers, so canceled requests cancel their queries
+	options.Handler.ContextWithFallback = true
+	registerFieldNames()
 	options.Handler.Use(gin.Logger(), gin.Recovery(), requestIDMiddleware, corsMiddleware)
 
 	routerOptions := RouterOptions{
-		Handler:  options.Handler.Group(""),
-		Services: options.Services,
-		Logger:   options.Logger.Named("HTTPController"),
-		Config:   options.Config,
+		// every route requires authentication and declares roles allowed on it,
+		// clients are limited by IP first, as authentication is not free
+		Handler:    options.Handler.Group("", limits.ipMiddleware(), auth.authenticate),
+		Services:   options.Services,
+		Logger:     options.Logger.Named("HTTPController"),
+		Config:     options.Config,
+		RateLimits: limits,
 	}
 
 	options.Handler.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
@@ -55,20 +87,29 @@ func New(options Options) {
 		newSpyCatRoutes(routerOptions)
 		newMissionRoutes(routerOptions)
 		newTargetRoutes(routerOptions)
+		newPayrollRoutes(routerOptions)
+		newBreedRoutes(routerOptions)
+		newAPIKeyRoutes(routerOptions)
 	}
+	return nil
 }
 
-// httpErr provides a base error type for all http controller errors
+// httpErr provides a base error type for all http controller errors.
+// It is rendered as a problem, Details of server errors are only logged.
 type httpErr struct {
-	Type    httpErrType `json:"-"`
-	Code    string      `json:"code,omitempty"`
-	Message string      `json:"message"`
-	Details interface{} `json:"details,omitempty"`
+	Type    httpErrType
+	Kind    errs.Kind
+	Code    string
+	Message string
+	Details interface{}
 }
 
 // httpErrType is used to define httpErr type
 type httpErrType string
 
+// statusClientClosedRequest is a non-standard status of requests canceled by clients.
+const statusClientClosedRequest = 499
+
 const (
 	// httpErrTypeServer is an "unexpected" internal server error
 	httpErrTypeServer httpErrType = "server"
@@ -81,6 +122,34 @@ func (err httpErr) Error() string {
 	return fmt.Sprintf("%s: %s", err.Type, err.Message)
 }
 
+// newClientErr converts an expected error to a client httpErr keeping its kind and code.
+func newClientErr(err error) *httpErr {
+	return &httpErr{
+		Type:    httpErrTypeClient,
+		Kind:    errs.KindOf(err),
+		Code:    errs.CodeOf(err),
+		Message: err.Error(),
+	}
+}
+
+// clientErrStatus maps the client error kind to http status, errors without kind are invalid requests.
+func clientErrStatus(kind errs.Kind) int {
+	switch kind {
+	case errs.KindNotFound:
+		return http.StatusNotFound
+	case errs.KindConflict:
+		return http.StatusConflict
+	case errs.KindForbidden:
+		return http.StatusForbidden
+	case errs.KindUnauthenticated:
+		return http.StatusUnauthorized
+	case errs.KindUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusBadRequest
+	}
+}
+
 // errorHandler provides unified error handling for all handlers.
 func errorHandler(options RouterOptions, handler func(c *gin.Context) (interface{}, *httpErr)) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -97,10 +166,7 @@ func errorHandler(options RouterOptions, handler func(c *gin.Context) (interface
 					logger.Error("unhandled error", "err", err, "stacktrace", stacktrace)
 				}
 
-				err := c.AbortWithError(http.StatusInternalServerError, fmt.Errorf("%v", err))
-				if err != nil {
-					logger.Error("failed to abort with error", "err", err)
-				}
+				abortWithProblem(c, newProblem(c, http.StatusInternalServerError, "", "internal server error"))
 			}
 		}()
 
@@ -115,12 +181,21 @@ func errorHandler(options RouterOptions, handler func(c *gin.Context) (interface
 		}
 
 		if err != nil {
-			if err.Type == httpErrTypeServer {
+			cause, _ := err.Details.(error)
+			if err.Type == httpErrTypeServer && (errors.Is(cause, postgresql.ErrCanceled) || errors.Is(cause, context.Canceled)) {
+				logger.Warn("request canceled")
+				abortWithProblem(c, newProblem(c, statusClientClosedRequest, "", "request canceled"))
+			} else if err.Type == httpErrTypeServer && (errors.Is(cause, postgresql.ErrTimeout) || errors.Is(cause, context.DeadlineExceeded)) {
+				logger.Error("request timed out")
+				abortWithProblem(c, newProblem(c, http.StatusGatewayTimeout, "", "request timed out"))
+			} else if err.Type == httpErrTypeServer {
 				logger.Error("internal server error")
-				c.AbortWithStatusJSON(http.StatusInternalServerError, err)
+				abortWithProblem(c, newProblem(c, http.StatusInternalServerError, "", err.Message))
 			} else {
 				logger.Info("client error")
-				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, err)
+				p := newProblem(c, clientErrStatus(err.Kind), err.Code, err.Message)
+				p.Errors = fieldErrors(cause)
+				abortWithProblem(c, p)
 			}
 			return
 		}
@@ -0,0 +1,166 @@
+package httpcontroller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/Kontentski/develops-today-task/config"
+	"github.com/Kontentski/develops-today-task/internal/entity"
+	"github.com/Kontentski/develops-today-task/internal/service"
+	"github.com/Kontentski/develops-today-task/pkg/logging"
+	"github.com/Kontentski/develops-today-task/pkg/postgresql"
+)
+
+const testJWTSecret = "0123456789abcdef0123456789abcdef"
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// newTestConfig returns a config accepted by New with rate limits turned off.
+func newTestConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.Auth.JWTSecret = testJWTSecret
+	cfg.RateLimit.Read = "0"
+	cfg.RateLimit.Write = "0"
+	cfg.RateLimit.IP = "0"
+	return cfg
+}
+
+// newTestHandler creates the http controller of passed services.
+func newTestHandler(t *testing.T, cfg *config.Config, services service.Services) *gin.Engine {
+	t.Helper()
+
+	handler := gin.New()
+	err := New(Options{
+		Handler:  handler,
+		Services: services,
+		Logger:   logging.NewZapLogger("error"),
+		Config:   cfg,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return handler
+}
+
+// testToken returns a bearer token of the user with passed role.
+func testToken(t *testing.T, role entity.Role) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, authClaims{
+		Role: role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "tester",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}).SignedString([]byte(testJWTSecret))
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+	return "Bearer " + token
+}
+
+// serve sends the request to the handler and returns the response.
+func serve(handler http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestIPRateLimitIgnoresForwardedFor(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.RateLimit.IP = "1/1m"
+	handler := newTestHandler(t, cfg, service.Services{})
+
+	for i, forwardedFor := range []string{"198.51.100.1", "198.51.100.2", "198.51.100.3"} {
+		req := httptest.NewRequest(http.MethodGet, "/spycats/", nil)
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		w := serve(handler, req)
+
+		want := http.StatusTooManyRequests
+		if i == 0 {
+			// passes the limit and fails authentication
+			want = http.StatusUnauthorized
+		}
+		if w.Code != want {
+			t.Fatalf("request %d with X-Forwarded-For %s status = %d, want %d", i, forwardedFor, w.Code, want)
+		}
+	}
+}
+
+func TestIPRateLimitTrustedProxy(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.RateLimit.IP = "1/1m"
+	// httptest requests come from 192.0.2.1
+	cfg.HTTP.TrustedProxies = []string{"192.0.2.0/24"}
+	handler := newTestHandler(t, cfg, service.Services{})
+
+	for _, forwardedFor := range []string{"198.51.100.1", "198.51.100.2"} {
+		req := httptest.NewRequest(http.MethodGet, "/spycats/", nil)
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		if w := serve(handler, req); w.Code != http.StatusUnauthorized {
+			t.Fatalf("first request of client %s status = %d, want %d", forwardedFor, w.Code, http.StatusUnauthorized)
+		}
+	}
+}
+
+func TestErrorHandlerServerErrors(t *testing.T) {
+	queryErr := func(err error) error {
+		return fmt.Errorf("failed to get mission: %w", postgresql.Error(err))
+	}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{
+			name:       "context canceled",
+			err:        queryErr(context.Canceled),
+			wantStatus: statusClientClosedRequest,
+		},
+		{
+			name:       "context deadline exceeded",
+			err:        queryErr(context.DeadlineExceeded),
+			wantStatus: http.StatusGatewayTimeout,
+		},
+		{
+			name:       "statement timeout",
+			err:        queryErr(&pgconn.PgError{Code: "57014", Message: "canceling statement due to statement timeout"}),
+			wantStatus: http.StatusGatewayTimeout,
+		},
+		{
+			name:       "statement canceled",
+			err:        queryErr(&pgconn.PgError{Code: "57014", Message: "canceling statement due to user request"}),
+			wantStatus: statusClientClosedRequest,
+		},
+		{
+			name:       "other",
+			err:        queryErr(errors.New("connection refused")),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newTestHandler(t, newTestConfig(), service.Services{
+				Mission: &fakeMissionService{getErr: tt.err},
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/missions/m1", nil)
+			req.Header.Set("Authorization", testToken(t, entity.RoleAdmin))
+			if w := serve(handler, req); w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body %s", w.Code, tt.wantStatus, w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package httpcontroller
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+
+	// third party
+	"github.com/gin-gonic/gin"
+
+	"github.com/Kontentski/develops-today-task/internal/service"
+	"github.com/Kontentski/develops-today-task/pkg/errs"
+)
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// idempotencyRecorder copies the response written by handlers, so it can be replayed.
+type idempotencyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *idempotencyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// idempotent - used to make the route safe to retry with an Idempotency-Key header.
+// The first response to a key is stored and replayed to retries of the same request,
+// server errors and panics are not stored, so the request can be retried.
+func idempotent(options RouterOptions) gin.HandlerFunc {
+	logger := options.Logger.Named("idempotent")
+
+	return func(c *gin.Context) {
+		header, ok := c.Request.Header[idempotencyKeyHeader]
+		if !ok {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			abortWithProblem(c, newProblem(c, http.StatusBadRequest, "", "failed to read request body"))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		key, err := options.Services.Idempotency.BeginIdempotentRequest(c, service.BeginIdempotentRequestOptions{
+			Key:         header[0],
+			Fingerprint: requestFingerprint(c, body),
+		})
+		if err != nil {
+			if errs.IsExpected(err) {
+				abortWithProblem(c, newProblem(c, clientErrStatus(errs.KindOf(err)), errs.CodeOf(err), err.Error()))
+				return
+			}
+			logger.Error("failed to begin idempotent request", "err", err)
+			abortWithProblem(c, newProblem(c, http.StatusInternalServerError, "", "failed to check idempotency key"))
+			return
+		}
+
+		if key.IsCompleted() {
+			c.Header(idempotentReplayedHeader, "true")
+			c.Data(key.ResponseStatus, key.ResponseContentType, key.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		recorder := &idempotencyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		handled := false
+		defer func() {
+			c.Writer = recorder.ResponseWriter
+
+			// the response is recorded even if the client is gone, its retry gets it
+			ctx := context.WithoutCancel(c.Request.Context())
+			status := recorder.Status()
+
+			// panics are recovered further up, the key is released before
+			if !handled || status >= http.StatusInternalServerError || status == statusClientClosedRequest {
+				if err := options.Services.Idempotency.ReleaseIdempotentRequest(ctx, key); err != nil {
+					logger.Error("failed to release idempotency key", "err", err)
+				}
+				return
+			}
+
+			err := options.Services.Idempotency.CompleteIdempotentRequest(ctx, key, service.CompleteIdempotentRequestOptions{
+				Status:      status,
+				ContentType: recorder.Header().Get("Content-Type"),
+				Body:        recorder.body.Bytes(),
+			})
+			if err != nil {
+				logger.Error("failed to complete idempotent request", "err", err)
+			}
+		}()
+
+		c.Next()
+		handled = true
+	}
+}
+
+// requestFingerprint hashes the method, path and body of the request.
+func requestFingerprint(c *gin.Context, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
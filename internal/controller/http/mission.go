@@ -1,6 +1,13 @@
 package httpcontroller
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Kontentski/develops-today-task/internal/entity"
 	"github.com/Kontentski/develops-today-task/internal/service"
 	"github.com/Kontentski/develops-today-task/pkg/errs"
 	"github.com/gin-gonic/gin"
@@ -19,20 +26,22 @@ func newMissionRoutes(options RouterOptions) {
 		},
 	}
 
-	p := options.Handler.Group("/missions")
+	p := options.Handler.Group("/missions", options.RateLimits.middleware("missions"))
 	{
-		p.POST("/", errorHandler(options, r.createMission))
-		p.DELETE("/:id", errorHandler(options, r.deleteMission))
-		p.GET("/", errorHandler(options, r.listMissions))
-		p.GET("/:id", errorHandler(options, r.getMission))
-		p.PUT("/:id", errorHandler(options, r.updateMission))
-		p.POST("/:id/assign", errorHandler(options, r.assignSpyCat))
+		p.POST("/", authorize(entity.ScopeMissionsWrite, rolesStaff...), idempotent(options), errorHandler(options, r.createMission))
+		p.DELETE("/:id", authorize(entity.ScopeMissionsWrite, rolesStaff...), errorHandler(options, r.deleteMission))
+		p.GET("/", authorize(entity.ScopeMissionsRead, rolesStaff...), errorHandler(options, r.listMissions))
+		p.GET("/:id", authorize(entity.ScopeMissionsRead, rolesAll...), errorHandler(options, r.getMission))
+		p.POST("/:id/transitions", authorize(entity.ScopeMissionsWrite, rolesStaff...), errorHandler(options, r.transitionMission))
+		p.POST("/:id/assign", authorize(entity.ScopeMissionsWrite, rolesStaff...), idempotent(options), errorHandler(options, r.assignSpyCat))
+		p.POST("/:id/unassign", authorize(entity.ScopeMissionsWrite, rolesStaff...), errorHandler(options, r.unassignSpyCat))
+		p.POST("/:id/reassign", authorize(entity.ScopeMissionsWrite, rolesStaff...), idempotent(options), errorHandler(options, r.reassignSpyCat))
+		p.GET("/:id/assignments", authorize(entity.ScopeMissionsRead, rolesStaff...), errorHandler(options, r.listMissionAssignments))
 	}
 }
 
 type createMissionRequest struct {
-	Completed bool                     `json:"completed"`
-	Targets   []createMissionTargetReq `json:"targets" binding:"required,min=1,max=3"`
+	Targets []createMissionTargetReq `json:"targets" binding:"required,min=1,max=3,dive"`
 }
 
 type createMissionTargetReq struct {
@@ -49,8 +58,7 @@ func (r *missionRoutes) createMission(c *gin.Context) (interface{}, *httpErr) {
 	}
 
 	opts := service.CreateMissionOptions{
-		Completed: req.Completed,
-		Targets:   make([]service.CreateTargetOptions, len(req.Targets)),
+		Targets: make([]service.CreateTargetOptions, len(req.Targets)),
 	}
 
 	for i, t := range req.Targets {
@@ -65,7 +73,7 @@ func (r *missionRoutes) createMission(c *gin.Context) (interface{}, *httpErr) {
 	mission, err := r.services.Mission.CreateMission(c, opts)
 	if err != nil {
 		if errs.IsExpected(err) {
-			return nil, &httpErr{Type: httpErrTypeClient, Message: err.Error()}
+			return nil, newClientErr(err)
 		}
 		return nil, &httpErr{Type: httpErrTypeServer, Message: "failed to create mission", Details: err}
 	}
@@ -78,7 +86,7 @@ func (r *missionRoutes) deleteMission(c *gin.Context) (interface{}, *httpErr) {
 
 	if err := r.services.Mission.DeleteMission(c, id); err != nil {
 		if errs.IsExpected(err) {
-			return nil, &httpErr{Type: httpErrTypeClient, Message: err.Error()}
+			return nil, newClientErr(err)
 		}
 		return nil, &httpErr{Type: httpErrTypeServer, Message: "failed to delete mission", Details: err}
 	}
@@ -86,25 +94,25 @@ func (r *missionRoutes) deleteMission(c *gin.Context) (interface{}, *httpErr) {
 	return gin.H{"message": "mission deleted successfully"}, nil
 }
 
-type updateMissionRequest struct {
-	Completed bool `json:"completed" binding:"required"`
+type transitionMissionRequest struct {
+	Status string `json:"status" binding:"required,oneof=draft assigned in_progress completed aborted"`
 }
 
-func (r *missionRoutes) updateMission(c *gin.Context) (interface{}, *httpErr) {
+func (r *missionRoutes) transitionMission(c *gin.Context) (interface{}, *httpErr) {
 	id := c.Param("id")
-	var req updateMissionRequest
+	var req transitionMissionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		return nil, &httpErr{Type: httpErrTypeClient, Message: "invalid request body", Details: err}
 	}
 
-	mission, err := r.services.Mission.UpdateMission(c, id, service.UpdateMissionOptions{
-		Completed: req.Completed,
+	mission, err := r.services.Mission.TransitionMission(c, id, service.TransitionMissionOptions{
+		Status: entity.MissionStatus(req.Status),
 	})
 	if err != nil {
 		if errs.IsExpected(err) {
-			return nil, &httpErr{Type: httpErrTypeClient, Message: err.Error()}
+			return nil, newClientErr(err)
 		}
-		return nil, &httpErr{Type: httpErrTypeServer, Message: "failed to update mission", Details: err}
+		return nil, &httpErr{Type: httpErrTypeServer, Message: "failed to transition mission", Details: err}
 	}
 
 	return mission, nil
@@ -116,7 +124,7 @@ func (r *missionRoutes) getMission(c *gin.Context) (interface{}, *httpErr) {
 	mission, err := r.services.Mission.GetMission(c, id)
 	if err != nil {
 		if errs.IsExpected(err) {
-			return nil, &httpErr{Type: httpErrTypeClient, Message: err.Error()}
+			return nil, newClientErr(err)
 		}
 		return nil, &httpErr{Type: httpErrTypeServer, Message: "failed to get mission", Details: err}
 	}
@@ -124,17 +132,77 @@ func (r *missionRoutes) getMission(c *gin.Context) (interface{}, *httpErr) {
 	return mission, nil
 }
 
+type listMissionsRequest struct {
+	Status        string     `form:"status" binding:"omitempty,oneof=draft assigned in_progress completed aborted"`
+	SpyCatID      string     `form:"spyCatId"`
+	Unassigned    bool       `form:"unassigned"`
+	TargetCountry string     `form:"targetCountry"`
+	CreatedFrom   *time.Time `form:"createdFrom"`
+	CreatedTo     *time.Time `form:"createdTo"`
+	Include       string     `form:"include"`
+	Cursor        string     `form:"cursor"`
+	Limit         int        `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// listMissions returns mission summaries unless full missions are requested via include.
 func (r *missionRoutes) listMissions(c *gin.Context) (interface{}, *httpErr) {
-	missions, err := r.services.Mission.ListMissions(c)
+	var req listMissionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		return nil, &httpErr{Type: httpErrTypeClient, Message: "invalid query parameters", Details: err}
+	}
+
+	cursor, err := service.DecodeCursor(req.Cursor)
+	if err != nil {
+		return nil, newClientErr(err)
+	}
+
+	opts := service.ListMissionsOptions{
+		SpyCatID:      req.SpyCatID,
+		Unassigned:    req.Unassigned,
+		TargetCountry: req.TargetCountry,
+		CreatedFrom:   req.CreatedFrom,
+		CreatedTo:     req.CreatedTo,
+		Cursor:        cursor,
+		Limit:         req.Limit,
+	}
+
+	if req.Status != "" {
+		status := entity.MissionStatus(req.Status)
+		opts.Status = &status
+	}
+
+	if req.Include != "" {
+		for _, include := range strings.Split(req.Include, ",") {
+			switch strings.TrimSpace(include) {
+			case "targets":
+				opts.IncludeTargets = true
+			case "spyCat":
+				opts.IncludeSpyCat = true
+			default:
+				return nil, &httpErr{Type: httpErrTypeClient, Message: fmt.Sprintf("unknown include %q", include)}
+			}
+		}
+	}
+
+	var page interface{}
+	if opts.IncludeTargets || opts.IncludeSpyCat {
+		page, err = r.services.Mission.ListMissions(c, opts)
+	} else {
+		page, err = r.services.Mission.ListMissionSummaries(c, opts)
+	}
 	if err != nil {
+		if errs.IsExpected(err) {
+			return nil, newClientErr(err)
+		}
 		return nil, &httpErr{Type: httpErrTypeServer, Message: "failed to list missions", Details: err}
 	}
 
-	return missions, nil
+	return page, nil
 }
 
 type assignSpyCatRequest struct {
 	SpyCatID string `json:"spyCatID" binding:"required"`
+	Reason   string `json:"reason"`
 }
 
 func (r *missionRoutes) assignSpyCat(c *gin.Context) (interface{}, *httpErr) {
@@ -144,12 +212,81 @@ func (r *missionRoutes) assignSpyCat(c *gin.Context) (interface{}, *httpErr) {
 		return nil, &httpErr{Type: httpErrTypeClient, Message: "invalid request body", Details: err}
 	}
 
-	if err := r.services.Mission.AssignSpyCat(c, id, req.SpyCatID); err != nil {
+	err := r.services.Mission.AssignSpyCat(c, id, service.AssignSpyCatOptions{
+		SpyCatID: req.SpyCatID,
+		Reason:   req.Reason,
+	})
+	if err != nil {
 		if errs.IsExpected(err) {
-			return nil, &httpErr{Type: httpErrTypeClient, Message: err.Error()}
+			return nil, newClientErr(err)
 		}
 		return nil, &httpErr{Type: httpErrTypeServer, Message: "failed to assign spy cat", Details: err}
 	}
 
 	return gin.H{"message": "spy cat assigned successfully"}, nil
 }
+
+type unassignSpyCatRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (r *missionRoutes) unassignSpyCat(c *gin.Context) (interface{}, *httpErr) {
+	id := c.Param("id")
+	var req unassignSpyCatRequest
+	// the body is optional, as it holds only the reason
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		return nil, &httpErr{Type: httpErrTypeClient, Message: "invalid request body", Details: err}
+	}
+
+	err := r.services.Mission.UnassignSpyCat(c, id, service.UnassignSpyCatOptions{
+		Reason: req.Reason,
+	})
+	if err != nil {
+		if errs.IsExpected(err) {
+			return nil, newClientErr(err)
+		}
+		return nil, &httpErr{Type: httpErrTypeServer, Message: "failed to unassign spy cat", Details: err}
+	}
+
+	return gin.H{"message": "spy cat unassigned successfully"}, nil
+}
+
+type reassignSpyCatRequest struct {
+	SpyCatID string `json:"spyCatID" binding:"required"`
+	Reason   string `json:"reason"`
+}
+
+func (r *missionRoutes) reassignSpyCat(c *gin.Context) (interface{}, *httpErr) {
+	id := c.Param("id")
+	var req reassignSpyCatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return nil, &httpErr{Type: httpErrTypeClient, Message: "invalid request body", Details: err}
+	}
+
+	err := r.services.Mission.ReassignSpyCat(c, id, service.ReassignSpyCatOptions{
+		SpyCatID: req.SpyCatID,
+		Reason:   req.Reason,
+	})
+	if err != nil {
+		if errs.IsExpected(err) {
+			return nil, newClientErr(err)
+		}
+		return nil, &httpErr{Type: httpErrTypeServer, Message: "failed to reassign spy cat", Details: err}
+	}
+
+	return gin.H{"message": "spy cat reassigned successfully"}, nil
+}
+
+func (r *missionRoutes) listMissionAssignments(c *gin.Context) (interface{}, *httpErr) {
+	id := c.Param("id")
+
+	assignments, err := r.services.Mission.ListMissionAssignments(c, id)
+	if err != nil {
+		if errs.IsExpected(err) {
+			return nil, newClientErr(err)
+		}
+		return nil, &httpErr{Type: httpErrTypeServer, Message: "failed to list mission assignments", Details: err}
+	}
+
+	return assignments, nil
+}
@@ -0,0 +1,66 @@
+package httpcontroller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Kontentski/develops-today-task/internal/entity"
+	"github.com/Kontentski/develops-today-task/internal/service"
+)
+
+// fakeMissionService records unassignments and fails gets with getErr, other methods are not implemented.
+type fakeMissionService struct {
+	service.MissionService
+	unassigned map[string]service.UnassignSpyCatOptions
+	getErr     error
+}
+
+func (s *fakeMissionService) GetMission(ctx context.Context, id string) (*entity.Mission, error) {
+	if s.getErr != nil {
+		return nil, s.getErr
+	}
+	return &entity.Mission{ID: id}, nil
+}
+
+func (s *fakeMissionService) UnassignSpyCat(ctx context.Context, missionID string, opts service.UnassignSpyCatOptions) error {
+	s.unassigned[missionID] = opts
+	return nil
+}
+
+func TestUnassignSpyCat(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantReason string
+	}{
+		{name: "no body", body: "", wantStatus: http.StatusOK},
+		{name: "reason", body: `{"reason":"on vacation"}`, wantStatus: http.StatusOK, wantReason: "on vacation"},
+		{name: "empty object", body: `{}`, wantStatus: http.StatusOK},
+		{name: "invalid json", body: `{"reason":`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			missions := &fakeMissionService{unassigned: map[string]service.UnassignSpyCatOptions{}}
+			handler := newTestHandler(t, newTestConfig(), service.Services{Mission: missions})
+
+			req := httptest.NewRequest(http.MethodPost, "/missions/m1/unassign", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", testToken(t, entity.RoleHandler))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			w := serve(handler, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body %s", w.Code, tt.wantStatus, w.Body)
+			}
+			opts, ok := missions.unassigned["m1"]
+			if ok != (tt.wantStatus == http.StatusOK) || opts.Reason != tt.wantReason {
+				t.Fatalf("UnassignSpyCat() called %v with %+v, want reason %q", ok, opts, tt.wantReason)
+			}
+		})
+	}
+}
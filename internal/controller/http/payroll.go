@@ -0,0 +1,116 @@
+package httpcontroller
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kontentski/develops-today-task/internal/entity"
+	"github.com/Kontentski/develops-today-task/internal/service"
+	"github.com/Kontentski/develops-today-task/pkg/errs"
+	"github.com/gin-gonic/gin"
+)
+
+type payrollRoutes struct {
+	routerContext
+}
+
+func newPayrollRoutes(options RouterOptions) {
+	r := &payrollRoutes{
+		routerContext{
+			services: options.Services,
+			logger:   options.Logger.Named("payrollRoutes"),
+			cfg:      options.Config,
+		},
+	}
+
+	p := options.Handler.Group("/payroll", options.RateLimits.middleware("payroll"))
+	{
+		p.GET("/", authorize(entity.ScopePayrollRead, rolesAdmin...), errorHandler(options, r.getPayroll))
+	}
+}
+
+type getPayrollRequest struct {
+	Month  string `form:"month" binding:"required"`
+	Format string `form:"format" binding:"omitempty,oneof=json csv"`
+}
+
+func (r *payrollRoutes) getPayroll(c *gin.Context) (interface{}, *httpErr) {
+	var req getPayrollRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		return nil, &httpErr{Type: httpErrTypeClient, Message: "invalid query parameters", Details: err}
+	}
+
+	month, err := time.Parse(service.PayrollMonthFormat, req.Month)
+	if err != nil {
+		return nil, &httpErr{Type: httpErrTypeClient, Message: "month must be in YYYY-MM format"}
+	}
+
+	report, err := r.services.Payroll.MonthlyReport(c, month)
+	if err != nil {
+		if errs.IsExpected(err) {
+			return nil, newClientErr(err)
+		}
+		return nil, &httpErr{Type: httpErrTypeServer, Message: "failed to build payroll report", Details: err}
+	}
+
+	if req.Format == "csv" || (req.Format == "" && strings.Contains(c.GetHeader("Accept"), "text/csv")) {
+		if err := writePayrollCSV(c, report); err != nil {
+			return nil, &httpErr{Type: httpErrTypeServer, Message: "failed to write payroll report", Details: err}
+		}
+		return nil, nil
+	}
+
+	return report, nil
+}
+
+// writePayrollCSV writes payroll report to the response as a CSV attachment.
+func writePayrollCSV(c *gin.Context, report *service.PayrollReport) error {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="payroll-%s.csv"`, report.Month))
+	c.Status(http.StatusOK)
+
+	formatAmount := func(amount float64) string {
+		return strconv.FormatFloat(amount, 'f', 2, 64)
+	}
+
+	w := csv.NewWriter(c.Writer)
+	err := w.Write([]string{
+		"spyCatId", "name", "breed", "employedFrom", "employedTo",
+		"basePay", "completedMissions", "bonus", "total",
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, line := range report.Lines {
+		err := w.Write([]string{
+			line.SpyCatID,
+			csvText(line.Name),
+			csvText(line.Breed),
+			line.EmployedFrom.Format(time.RFC3339),
+			line.EmployedTo.Format(time.RFC3339),
+			formatAmount(line.BasePay),
+			strconv.Itoa(line.CompletedMissions),
+			formatAmount(line.Bonus),
+			formatAmount(line.Total),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// csvText escapes user provided text, so spreadsheets do not run it as a formula.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,82 @@
+package httpcontroller
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Kontentski/develops-today-task/internal/entity"
+	"github.com/Kontentski/develops-today-task/internal/service"
+)
+
+// fakePayrollService returns the report of a single line.
+type fakePayrollService struct {
+	line service.PayrollLine
+}
+
+func (s *fakePayrollService) MonthlyReport(ctx context.Context, month time.Time) (*service.PayrollReport, error) {
+	return &service.PayrollReport{
+		Month: month.Format(service.PayrollMonthFormat),
+		Lines: []service.PayrollLine{s.line},
+		Total: s.line.Total,
+	}, nil
+}
+
+func TestCSVText(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{s: "", want: ""},
+		{s: "Tom", want: "Tom"},
+		{s: "Tom = Jerry", want: "Tom = Jerry"},
+		{s: `=HYPERLINK("http://evil","x")`, want: `'=HYPERLINK("http://evil","x")`},
+		{s: "+1", want: "'+1"},
+		{s: "-1", want: "'-1"},
+		{s: "@SUM(A1)", want: "'@SUM(A1)"},
+		{s: "\t=1", want: "'\t=1"},
+		{s: "\r=1", want: "'\r=1"},
+	}
+	for _, tt := range tests {
+		if got := csvText(tt.s); got != tt.want {
+			t.Errorf("csvText(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestGetPayrollCSVEscapesFormulas(t *testing.T) {
+	payroll := &fakePayrollService{line: service.PayrollLine{
+		SpyCatID: "c1",
+		Name:     `=HYPERLINK("http://evil","x")`,
+		Breed:    "@Bengal",
+		BasePay:  -10,
+		Total:    -10,
+	}}
+	handler := newTestHandler(t, newTestConfig(), service.Services{Payroll: payroll})
+
+	req := httptest.NewRequest(http.MethodGet, "/payroll/?month=2026-09&format=csv", nil)
+	req.Header.Set("Authorization", testToken(t, entity.RoleAdmin))
+	w := serve(handler, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("csv has %d records, want header and a line", len(records))
+	}
+	line := records[1]
+	if line[1] != `'=HYPERLINK("http://evil","x")` || line[2] != "'@Bengal" {
+		t.Fatalf("csv name and breed = %q, %q, want escaped", line[1], line[2])
+	}
+	// amounts are generated, so negative ones are kept as numbers
+	if line[5] != "-10.00" {
+		t.Fatalf("csv base pay = %q, want -10.00", line[5])
+	}
+}
@@ -0,0 +1,175 @@
+package httpcontroller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	// third party
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// problemContentType is the media type of RFC 7807 error responses.
+const problemContentType = "application/problem+json"
+
+// problem is an RFC 7807 error response, code is an extension member with the stable error code.
+type problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code,omitempty"`
+	Errors   []fieldError `json:"errors,omitempty"`
+}
+
+// fieldError describes a single invalid request field.
+type fieldError struct {
+	// Field is the path of the field in the request, e.g. targets[0].name.
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// newProblem creates a problem of the request, errors with code get a dedicated type.
+func newProblem(c *gin.Context, status int, code, detail string) *problem {
+	p := &problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+
+	if code != "" {
+		p.Type = "urn:problem:" + code
+	}
+	if status == statusClientClosedRequest {
+		p.Title = "Client Closed Request"
+	}
+	if requestID := c.GetString("RequestID"); requestID != "" {
+		p.Instance = "urn:uuid:" + requestID
+	}
+	return p
+}
+
+// abortWithProblem writes the problem and aborts the request.
+func abortWithProblem(c *gin.Context, p *problem) {
+	c.Header("Content-Type", problemContentType)
+	c.AbortWithStatusJSON(p.Status, p)
+}
+
+// registerFieldNames makes validation errors refer to fields by their json or form names.
+func registerFieldNames() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "form"} {
+			name := strings.Split(field.Tag.Get(tag), ",")[0]
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return field.Name
+	})
+}
+
+// fieldErrors converts request binding errors to field errors, nil if err does not describe fields.
+func fieldErrors(err error) []fieldError {
+	var (
+		validationErrs validator.ValidationErrors
+		typeErr        *json.UnmarshalTypeError
+	)
+
+	switch {
+	case errors.As(err, &validationErrs):
+		fields := make([]fieldError, len(validationErrs))
+		for i, e := range validationErrs {
+			fields[i] = fieldError{
+				Field:   fieldPath(e.Namespace()),
+				Rule:    e.Tag(),
+				Message: ruleMessage(e),
+			}
+		}
+		return fields
+
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return []fieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: "must be " + jsonType(typeErr.Type.Kind()),
+		}}
+	}
+	return nil
+}
+
+// fieldPath strips the request struct name from the validator namespace.
+func fieldPath(namespace string) string {
+	if _, path, ok := strings.Cut(namespace, "."); ok {
+		return path
+	}
+	return namespace
+}
+
+// ruleMessage returns a human readable message of the failed validation rule.
+func ruleMessage(e validator.FieldError) string {
+	switch e.Tag() {
+	case "required":
+		return "is required"
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", e.Param())
+	case "gte":
+		return fmt.Sprintf("must be greater than or equal to %s", e.Param())
+	case "lt":
+		return fmt.Sprintf("must be less than %s", e.Param())
+	case "lte":
+		return fmt.Sprintf("must be less than or equal to %s", e.Param())
+	case "min":
+		if isCollection(e.Kind()) {
+			return fmt.Sprintf("must contain at least %s items", e.Param())
+		}
+		return fmt.Sprintf("must be at least %s", e.Param())
+	case "max":
+		if isCollection(e.Kind()) {
+			return fmt.Sprintf("must contain at most %s items", e.Param())
+		}
+		return fmt.Sprintf("must be at most %s", e.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", strings.ReplaceAll(e.Param(), " ", ", "))
+	default:
+		return fmt.Sprintf("failed %q validation", e.Tag())
+	}
+}
+
+func isCollection(kind reflect.Kind) bool {
+	return kind == reflect.Slice || kind == reflect.Array || kind == reflect.Map
+}
+
+// jsonType returns the JSON type name of values of the kind.
+func jsonType(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	default:
+		return "a string"
+	}
+}
@@ -0,0 +1,124 @@
+package httpcontroller
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	// third party
+	"github.com/gin-gonic/gin"
+
+	"github.com/Kontentski/develops-today-task/config"
+	"github.com/Kontentski/develops-today-task/internal/entity"
+	"github.com/Kontentski/develops-today-task/pkg/ratelimit"
+)
+
+// rateLimits holds configured limits of route groups.
+type rateLimits struct {
+	read   ratelimit.Limit
+	write  ratelimit.Limit
+	ip     ratelimit.Limit
+	groups map[string]ratelimit.Limit
+}
+
+// newRateLimits parses configured limits, group limits are keyed by <group>.<read|write>.
+func newRateLimits(cfg config.RateLimit) (*rateLimits, error) {
+	read, err := ratelimit.ParseLimit(cfg.Read)
+	if err != nil {
+		return nil, err
+	}
+	write, err := ratelimit.ParseLimit(cfg.Write)
+	if err != nil {
+		return nil, err
+	}
+	ip, err := ratelimit.ParseLimit(cfg.IP)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string]ratelimit.Limit, len(cfg.Groups))
+	for key, value := range cfg.Groups {
+		if _, access, _ := strings.Cut(key, "."); access != "read" && access != "write" {
+			return nil, fmt.Errorf("invalid rate limit group %q: must be <group>.read or <group>.write", key)
+		}
+		groups[key], err = ratelimit.ParseLimit(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &rateLimits{read: read, write: write, ip: ip, groups: groups}, nil
+}
+
+// limit returns the limit of the group reads or writes.
+func (r *rateLimits) limit(group, access string, fallback ratelimit.Limit) ratelimit.Limit {
+	if limit, ok := r.groups[group+"."+access]; ok {
+		return limit
+	}
+	return fallback
+}
+
+// ipMiddleware - used to limit requests by the client IP before they are authenticated,
+// so clients with invalid credentials cannot flood credential lookups.
+func (r *rateLimits) ipMiddleware() gin.HandlerFunc {
+	limiter := ratelimit.New(r.ip)
+
+	return func(c *gin.Context) {
+		limitRequest(c, limiter, "ip:"+c.ClientIP())
+	}
+}
+
+// middleware - used to limit requests of the route group by the authenticated client.
+// Safe requests are reads, others are writes, and both have their own buckets.
+func (r *rateLimits) middleware(group string) gin.HandlerFunc {
+	read := ratelimit.New(r.limit(group, "read", r.read))
+	write := ratelimit.New(r.limit(group, "write", r.write))
+
+	return func(c *gin.Context) {
+		limiter := write
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			limiter = read
+		}
+
+		limitRequest(c, limiter, rateLimitKey(principalOf(c)))
+	}
+}
+
+// limitRequest takes a token of the key and aborts the request if there is none.
+// Headers of a later limiter overwrite those of an earlier one.
+func limitRequest(c *gin.Context, limiter *ratelimit.Limiter, key string) {
+	result := limiter.Allow(key)
+	if result.Limit == 0 {
+		// unlimited
+		c.Next()
+		return
+	}
+
+	c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+
+	if !result.Allowed {
+		c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+		abortWithProblem(c, newProblem(c, http.StatusTooManyRequests, "rate_limited", "too many requests, retry later"))
+		return
+	}
+	c.Next()
+}
+
+// rateLimitKey identifies the authenticated client by API key or JWT subject.
+func rateLimitKey(principal *entity.Principal) string {
+	if principal.Kind == entity.PrincipalAPIKey {
+		return "api_key:" + principal.ID
+	}
+	return "user:" + principal.ID
+}
+
+// ceilSeconds rounds the duration up to whole seconds.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
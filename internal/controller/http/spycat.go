@@ -1,6 +1,10 @@
 package httpcontroller
 
 import (
+	"strings"
+	"time"
+
+	"github.com/Kontentski/develops-today-task/internal/entity"
 	"github.com/Kontentski/develops-today-task/internal/service"
 	"github.com/Kontentski/develops-today-task/pkg/errs"
 	"github.com/gin-gonic/gin"
@@ -19,13 +23,17 @@ func newSpyCatRoutes(options RouterOptions) {
 		},
 	}
 
-	p := options.Handler.Group("/spycats")
+	p := options.Handler.Group("/spycats", options.RateLimits.middleware("spycats"))
 	{
-		p.POST("/", errorHandler(options, r.createSpyCat))
-		p.DELETE("/:id", errorHandler(options, r.deleteSpyCat))
-		p.GET("/", errorHandler(options, r.listSpyCats))
-		p.GET("/:id", errorHandler(options, r.getSpyCat))
-		p.PUT("/:id/salary", errorHandler(options, r.updateSpyCatSalary))
+		p.POST("/", authorize(entity.ScopeSpyCatsWrite, rolesStaff...), idempotent(options), errorHandler(options, r.createSpyCat))
+		p.DELETE("/:id", authorize(entity.ScopeSpyCatsWrite, rolesAdmin...), errorHandler(options, r.deleteSpyCat))
+		p.GET("/", authorize(entity.ScopeSpyCatsRead, rolesStaff...), errorHandler(options, r.listSpyCats))
+		p.GET("/:id", authorize(entity.ScopeSpyCatsRead, rolesStaff...), errorHandler(options, r.getSpyCat))
+		p.PUT("/:id/salary", authorize(entity.ScopeSalariesWrite, rolesAdmin...), errorHandler(options, r.updateSpyCatSalary))
+		p.POST("/:id/salary-changes", authorize(entity.ScopeSalariesWrite, rolesAdmin...), idempotent(options), errorHandler(options, r.scheduleSalaryChange))
+		p.GET("/:id/salary-history", authorize(entity.ScopeSalariesRead, rolesAdmin...), errorHandler(options, r.listSalaryHistory))
+		p.GET("/:id/assignments", authorize(entity.ScopeSpyCatsRead, rolesStaff...), errorHandler(options, r.listSpyCatAssignments))
+		p.POST("/breed-profiles/refresh", authorize(entity.ScopeBreedsWrite, rolesAdmin...), errorHandler(options, r.refreshBreedProfiles))
 	}
 }
 
@@ -52,7 +60,7 @@ func (r *spyCatRoutes) createSpyCat(c *gin.Context) (interface{}, *httpErr) {
 	cat, err := r.services.SpyCat.CreateSpyCat(c, opts)
 	if err != nil {
 		if errs.IsExpected(err) {
-			return nil, &httpErr{Type: httpErrTypeClient, Message: err.Error()}
+			return nil, newClientErr(err)
 		}
 		return nil, &httpErr{Type: httpErrTypeServer, Message: "failed to create spy cat", Details: err}
 	}
@@ -65,7 +73,7 @@ func (r *spyCatRoutes) deleteSpyCat(c *gin.Context) (interface{}, *httpErr) {
 
 	if err := r.services.SpyCat.DeleteSpyCat(c, id); err != nil {
 		if errs.IsExpected(err) {
-			return nil, &httpErr{Type: httpErrTypeClient, Message: err.Error()}
+			return nil, newClientErr(err)
 		}
 		return nil, &httpErr{Type: httpErrTypeServer, Message: "failed to delete spy cat", Details: err}
 	}
@@ -75,6 +83,7 @@ func (r *spyCatRoutes) deleteSpyCat(c *gin.Context) (interface{}, *httpErr) {
 
 type updateSpyCatSalaryRequest struct {
 	Salary float64 `json:"salary" binding:"required,gt=0"`
+	Reason string  `json:"reason"`
 }
 
 func (r *spyCatRoutes) updateSpyCatSalary(c *gin.Context) (interface{}, *httpErr) {
@@ -84,21 +93,83 @@ func (r *spyCatRoutes) updateSpyCatSalary(c *gin.Context) (interface{}, *httpErr
 		return nil, &httpErr{Type: httpErrTypeClient, Message: "invalid request body", Details: err}
 	}
 
-	cat, err := r.services.SpyCat.UpdateSpyCatSalary(c, id, req.Salary)
+	cat, err := r.services.SpyCat.UpdateSpyCatSalary(c, id, service.UpdateSpyCatSalaryOptions{
+		Salary: req.Salary,
+		Reason: req.Reason,
+	})
 	if err != nil {
+		if errs.IsExpected(err) {
+			return nil, newClientErr(err)
+		}
 		return nil, &httpErr{Type: httpErrTypeServer, Message: "failed to update salary", Details: err}
 	}
 
 	return cat, nil
 }
 
+type scheduleSalaryChangeRequest struct {
+	Salary      float64   `json:"salary" binding:"required,gt=0"`
+	EffectiveAt time.Time `json:"effectiveAt" binding:"required"`
+	Reason      string    `json:"reason"`
+}
+
+func (r *spyCatRoutes) scheduleSalaryChange(c *gin.Context) (interface{}, *httpErr) {
+	id := c.Param("id")
+	var req scheduleSalaryChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return nil, &httpErr{Type: httpErrTypeClient, Message: "invalid request body", Details: err}
+	}
+
+	change, err := r.services.SpyCat.ScheduleSalaryChange(c, id, service.ScheduleSalaryChangeOptions{
+		Salary:      req.Salary,
+		EffectiveAt: req.EffectiveAt,
+		Reason:      req.Reason,
+	})
+	if err != nil {
+		if errs.IsExpected(err) {
+			return nil, newClientErr(err)
+		}
+		return nil, &httpErr{Type: httpErrTypeServer, Message: "failed to schedule salary change", Details: err}
+	}
+
+	return change, nil
+}
+
+func (r *spyCatRoutes) listSalaryHistory(c *gin.Context) (interface{}, *httpErr) {
+	id := c.Param("id")
+
+	changes, err := r.services.SpyCat.ListSalaryHistory(c, id)
+	if err != nil {
+		if errs.IsExpected(err) {
+			return nil, newClientErr(err)
+		}
+		return nil, &httpErr{Type: httpErrTypeServer, Message: "failed to list salary history", Details: err}
+	}
+
+	return changes, nil
+}
+
+func (r *spyCatRoutes) listSpyCatAssignments(c *gin.Context) (interface{}, *httpErr) {
+	id := c.Param("id")
+
+	assignments, err := r.services.SpyCat.ListSpyCatAssignments(c, id)
+	if err != nil {
+		if errs.IsExpected(err) {
+			return nil, newClientErr(err)
+		}
+		return nil, &httpErr{Type: httpErrTypeServer, Message: "failed to list spy cat assignments", Details: err}
+	}
+
+	return assignments, nil
+}
+
 func (r *spyCatRoutes) getSpyCat(c *gin.Context) (interface{}, *httpErr) {
 	id := c.Param("id")
 
 	cat, err := r.services.SpyCat.GetSpyCat(c, id)
 	if err != nil {
 		if errs.IsExpected(err) {
-			return nil, &httpErr{Type: httpErrTypeClient, Message: err.Error()}
+			return nil, newClientErr(err)
 		}
 		return nil, &httpErr{Type: httpErrTypeServer, Message: "failed to get spy cat", Details: err}
 	}
@@ -106,11 +177,61 @@ func (r *spyCatRoutes) getSpyCat(c *gin.Context) (interface{}, *httpErr) {
 	return cat, nil
 }
 
+type listSpyCatsRequest struct {
+	Breed         string   `form:"breed"`
+	MinExperience *int     `form:"minExperience" binding:"omitempty,gte=0"`
+	MaxExperience *int     `form:"maxExperience" binding:"omitempty,gte=0"`
+	MinSalary     *float64 `form:"minSalary" binding:"omitempty,gte=0"`
+	MaxSalary     *float64 `form:"maxSalary" binding:"omitempty,gte=0"`
+	Available     *bool    `form:"available"`
+	Sort          string   `form:"sort" binding:"omitempty,oneof=createdAt -createdAt name -name yearsOfExperience -yearsOfExperience salary -salary"`
+	Cursor        string   `form:"cursor"`
+	Limit         int      `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
 func (r *spyCatRoutes) listSpyCats(c *gin.Context) (interface{}, *httpErr) {
-	cats, err := r.services.SpyCat.ListSpyCats(c)
+	var req listSpyCatsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		return nil, &httpErr{Type: httpErrTypeClient, Message: "invalid query parameters", Details: err}
+	}
+
+	cursor, err := service.DecodeCursor(req.Cursor)
+	if err != nil {
+		return nil, newClientErr(err)
+	}
+
+	opts := service.ListSpyCatsOptions{
+		Breed:         req.Breed,
+		MinExperience: req.MinExperience,
+		MaxExperience: req.MaxExperience,
+		MinSalary:     req.MinSalary,
+		MaxSalary:     req.MaxSalary,
+		Available:     req.Available,
+		Sort:          service.SpyCatSort(strings.TrimPrefix(req.Sort, "-")),
+		Desc:          strings.HasPrefix(req.Sort, "-"),
+		Cursor:        cursor,
+		Limit:         req.Limit,
+	}
+
+	page, err := r.services.SpyCat.ListSpyCats(c, opts)
 	if err != nil {
+		if errs.IsExpected(err) {
+			return nil, newClientErr(err)
+		}
 		return nil, &httpErr{Type: httpErrTypeServer, Message: "failed to list spy cats", Details: err}
 	}
 
-	return cats, nil
+	return page, nil
+}
+
+func (r *spyCatRoutes) refreshBreedProfiles(c *gin.Context) (interface{}, *httpErr) {
+	refresh, err := r.services.Breed.RefreshBreedProfiles(c)
+	if err != nil {
+		if errs.IsExpected(err) {
+			return nil, newClientErr(err)
+		}
+		return nil, &httpErr{Type: httpErrTypeServer, Message: "failed to refresh breed profiles", Details: err}
+	}
+
+	return refresh, nil
 }
@@ -1,6 +1,7 @@
 package httpcontroller
 
 import (
+	"github.com/Kontentski/develops-today-task/internal/entity"
 	"github.com/Kontentski/develops-today-task/internal/service"
 	"github.com/Kontentski/develops-today-task/pkg/errs"
 	"github.com/gin-gonic/gin"
@@ -19,17 +20,20 @@ func newTargetRoutes(options RouterOptions) {
 		},
 	}
 
+	// both groups share the buckets of the targets limit
+	limit := options.RateLimits.middleware("targets")
+
 	// Standalone target operations
-	p := options.Handler.Group("/targets")
+	p := options.Handler.Group("/targets", limit)
 	{
-		p.PUT("/:id", errorHandler(options, r.updateTarget))
-		p.DELETE("/:id", errorHandler(options, r.deleteTarget))
+		p.PUT("/:id", authorize(entity.ScopeTargetsWrite, rolesAll...), errorHandler(options, r.updateTarget))
+		p.DELETE("/:id", authorize(entity.ScopeTargetsWrite, rolesStaff...), errorHandler(options, r.deleteTarget))
 	}
 
-	m := options.Handler.Group("/missions/:id")
+	m := options.Handler.Group("/missions/:id", limit)
 	{
-		m.POST("/targets", errorHandler(options, r.createTarget))
-		m.GET("/targets", errorHandler(options, r.listTargets))
+		m.POST("/targets", authorize(entity.ScopeTargetsWrite, rolesStaff...), idempotent(options), errorHandler(options, r.createTarget))
+		m.GET("/targets", authorize(entity.ScopeTargetsRead, rolesStaff...), errorHandler(options, r.listTargets))
 	}
 }
 
@@ -57,7 +61,7 @@ func (r *targetRoutes) createTarget(c *gin.Context) (interface{}, *httpErr) {
 	target, err := r.services.Target.CreateTarget(c, missionID, opts)
 	if err != nil {
 		if errs.IsExpected(err) {
-			return nil, &httpErr{Type: httpErrTypeClient, Message: err.Error()}
+			return nil, newClientErr(err)
 		}
 		return nil, &httpErr{Type: httpErrTypeServer, Message: "failed to create target", Details: err}
 	}
@@ -85,7 +89,7 @@ func (r *targetRoutes) updateTarget(c *gin.Context) (interface{}, *httpErr) {
 	target, err := r.services.Target.UpdateTarget(c, id, opts)
 	if err != nil {
 		if errs.IsExpected(err) {
-			return nil, &httpErr{Type: httpErrTypeClient, Message: err.Error()}
+			return nil, newClientErr(err)
 		}
 		return nil, &httpErr{Type: httpErrTypeServer, Message: "failed to update target", Details: err}
 	}
@@ -98,7 +102,7 @@ func (r *targetRoutes) deleteTarget(c *gin.Context) (interface{}, *httpErr) {
 
 	if err := r.services.Target.DeleteTarget(c, id); err != nil {
 		if errs.IsExpected(err) {
-			return nil, &httpErr{Type: httpErrTypeClient, Message: err.Error()}
+			return nil, newClientErr(err)
 		}
 		return nil, &httpErr{Type: httpErrTypeServer, Message: "failed to delete target", Details: err}
 	}
@@ -0,0 +1,60 @@
+package entity
+
+import (
+	"slices"
+	"time"
+)
+
+// Scope is an operation an API key is allowed to do.
+type Scope string
+
+const (
+	ScopeSpyCatsRead   Scope = "spycats:read"
+	ScopeSpyCatsWrite  Scope = "spycats:write"
+	ScopeSalariesRead  Scope = "salaries:read"
+	ScopeSalariesWrite Scope = "salaries:write"
+	ScopeMissionsRead  Scope = "missions:read"
+	ScopeMissionsWrite Scope = "missions:write"
+	ScopeTargetsRead   Scope = "targets:read"
+	ScopeTargetsWrite  Scope = "targets:write"
+	ScopePayrollRead   Scope = "payroll:read"
+	ScopeBreedsRead    Scope = "breeds:read"
+	ScopeBreedsWrite   Scope = "breeds:write"
+)
+
+// Scopes lists all known scopes.
+var Scopes = []Scope{
+	ScopeSpyCatsRead, ScopeSpyCatsWrite,
+	ScopeSalariesRead, ScopeSalariesWrite,
+	ScopeMissionsRead, ScopeMissionsWrite,
+	ScopeTargetsRead, ScopeTargetsWrite,
+	ScopePayrollRead,
+	ScopeBreedsRead, ScopeBreedsWrite,
+}
+
+// IsValid reports whether s is a known scope.
+func (s Scope) IsValid() bool {
+	return slices.Contains(Scopes, s)
+}
+
+// APIKey is a credential of a machine client. Only the hash of the key is stored,
+// Prefix is the public part of the key used to find it.
+type APIKey struct {
+	ID         string     `json:"id,omitempty" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Name       string     `json:"name" gorm:"not null"`
+	Prefix     string     `json:"prefix" gorm:"not null;uniqueIndex"`
+	Hash       string     `json:"-" gorm:"not null"`
+	Scopes     []Scope    `json:"scopes" gorm:"serializer:json;type:jsonb;not null"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	// CreatedBy is the ID of the principal that created the key.
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt,omitempty"`
+}
+
+// IsActive reports whether the key can be used at passed time.
+func (k *APIKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+// Assignment represents a period a spy cat worked on a mission.
+// An assignment with EndedAt unset is the active one.
+type Assignment struct {
+	ID        string     `json:"id,omitempty" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	SpyCatID  string     `json:"spyCatId" gorm:"type:uuid;not null;index"`
+	MissionID string     `json:"missionId" gorm:"type:uuid;not null;index"`
+	StartedAt time.Time  `json:"startedAt" gorm:"not null"`
+	EndedAt   *time.Time `json:"endedAt,omitempty"`
+	Reason    string     `json:"reason"`
+	EndReason string     `json:"endReason,omitempty"`
+	CreatedAt time.Time  `json:"createdAt,omitempty"`
+	UpdatedAt time.Time  `json:"updatedAt,omitempty"`
+}
@@ -0,0 +1,47 @@
+package entity
+
+import "time"
+
+// Breed represents a cat breed spy cats can be of.
+// Breeds are synced from TheCatAPI, custom breeds are added by admins.
+type Breed struct {
+	// ID is TheCatAPI breed ID or a slug of a custom breed name.
+	ID          string    `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"not null"`
+	Origin      string    `json:"origin"`
+	Temperament string    `json:"temperament"`
+	Description string    `json:"description"`
+	Custom      bool      `json:"custom"`
+	CreatedAt   time.Time `json:"createdAt,omitempty"`
+	UpdatedAt   time.Time `json:"updatedAt,omitempty"`
+}
+
+// Profile returns metadata of the breed captured on spy cats.
+func (b *Breed) Profile() BreedProfile {
+	return BreedProfile{
+		Origin:      b.Origin,
+		Temperament: b.Temperament,
+		Description: b.Description,
+	}
+}
+
+// BreedSync records a successful sync of breeds with TheCatAPI.
+type BreedSync struct {
+	ID       string    `json:"id,omitempty" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Count    int       `json:"count"`
+	SyncedAt time.Time `json:"syncedAt" gorm:"not null;index"`
+}
+
+// BreedStats aggregates spy cats of a breed and their missions.
+type BreedStats struct {
+	BreedID           string  `json:"breedId"`
+	ActiveCats        int     `json:"activeCats"`
+	AverageSalary     float64 `json:"averageSalary"`
+	AverageExperience float64 `json:"averageExperience"`
+	// CompletedMissions and FinishedMissions count missions of all cats of the breed, including deleted ones.
+	CompletedMissions int `json:"completedMissions"`
+	// FinishedMissions are completed or aborted missions.
+	FinishedMissions int `json:"finishedMissions"`
+	// MissionCompletionRate is the share of finished missions that were completed, nil if none are finished.
+	MissionCompletionRate *float64 `json:"missionCompletionRate"`
+}
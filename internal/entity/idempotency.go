@@ -0,0 +1,26 @@
+package entity
+
+import "time"
+
+// IdempotencyKey records a request sent with an Idempotency-Key header and its response,
+// so retries of the request replay the response instead of repeating the request.
+type IdempotencyKey struct {
+	ID string `json:"id,omitempty" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	// Owner identifies the principal that sent the request, so keys of different clients do not collide.
+	Owner string `json:"owner" gorm:"not null;uniqueIndex:idx_idempotency_keys_owner_key"`
+	Key   string `json:"key" gorm:"not null;uniqueIndex:idx_idempotency_keys_owner_key"`
+	// Fingerprint is a hash of the request method, path and body.
+	Fingerprint string `json:"fingerprint" gorm:"not null"`
+	// ResponseStatus is zero while the request is in progress.
+	ResponseStatus      int       `json:"responseStatus"`
+	ResponseContentType string    `json:"responseContentType"`
+	ResponseBody        []byte    `json:"-"`
+	ExpiresAt           time.Time `json:"expiresAt" gorm:"not null;index"`
+	CreatedAt           time.Time `json:"createdAt,omitempty"`
+	UpdatedAt           time.Time `json:"updatedAt,omitempty"`
+}
+
+// IsCompleted reports whether the response of the request is recorded.
+func (k *IdempotencyKey) IsCompleted() bool {
+	return k.ResponseStatus != 0
+}
@@ -8,12 +8,66 @@ import (
 
 // Mission represents a mission undertaken by a spy cat.
 type Mission struct {
-	ID        string         `json:"id,omitempty" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" binding:"required"`
-	SpyCatID  *string        `json:"spyCatId,omitempty"`
-	SpyCat    *SpyCat         `json:"spyCat,omitempty" gorm:"foreignKey:SpyCatID"`
-	Targets   []Target       `json:"targets" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
-	Completed bool           `json:"completed" binding:"required"`
-	CreatedAt time.Time      `json:"createdAt,omitempty" gorm:"index"`
-	UpdatedAt time.Time      `json:"updatedAt,omitempty"`
-	DeletedAt gorm.DeletedAt `json:"deletedAt,omitempty" gorm:"index"`
+	ID          string         `json:"id,omitempty" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" binding:"required"`
+	SpyCatID    *string        `json:"spyCatId,omitempty"`
+	SpyCat      *SpyCat        `json:"spyCat,omitempty" gorm:"foreignKey:SpyCatID"`
+	Targets     []Target       `json:"targets" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Status      MissionStatus  `json:"status" gorm:"type:varchar(16);not null;default:draft;index"`
+	CompletedAt *time.Time     `json:"completedAt,omitempty" gorm:"index"`
+	CreatedAt   time.Time      `json:"createdAt,omitempty" gorm:"index"`
+	UpdatedAt   time.Time      `json:"updatedAt,omitempty"`
+	DeletedAt   gorm.DeletedAt `json:"deletedAt,omitempty" gorm:"index"`
+}
+
+// MissionStatus is a stage of the mission lifecycle.
+type MissionStatus string
+
+const (
+	MissionStatusDraft      MissionStatus = "draft"
+	MissionStatusAssigned   MissionStatus = "assigned"
+	MissionStatusInProgress MissionStatus = "in_progress"
+	MissionStatusCompleted  MissionStatus = "completed"
+	MissionStatusAborted    MissionStatus = "aborted"
+)
+
+// missionTransitions lists statuses every status can move to.
+// Completed and aborted are final statuses.
+var missionTransitions = map[MissionStatus][]MissionStatus{
+	MissionStatusDraft:      {MissionStatusAssigned, MissionStatusAborted},
+	MissionStatusAssigned:   {MissionStatusDraft, MissionStatusInProgress, MissionStatusAborted},
+	MissionStatusInProgress: {MissionStatusDraft, MissionStatusCompleted, MissionStatusAborted},
+	MissionStatusCompleted:  {},
+	MissionStatusAborted:    {},
+}
+
+// IsValid reports whether the status is a known status.
+func (s MissionStatus) IsValid() bool {
+	_, ok := missionTransitions[s]
+	return ok
+}
+
+// IsFinal reports whether the mission lifecycle is over.
+func (s MissionStatus) IsFinal() bool {
+	return s == MissionStatusCompleted || s == MissionStatusAborted
+}
+
+// CanTransitionTo reports whether the mission can move from the status to the next one.
+func (s MissionStatus) CanTransitionTo(next MissionStatus) bool {
+	for _, status := range missionTransitions[s] {
+		if status == next {
+			return true
+		}
+	}
+	return false
+}
+
+// MissionSummary is a lightweight projection of a Mission with target counts instead of target rows.
+type MissionSummary struct {
+	ID                   string        `json:"id"`
+	SpyCatID             *string       `json:"spyCatId,omitempty"`
+	Status               MissionStatus `json:"status"`
+	TargetCount          int           `json:"targetCount"`
+	CompletedTargetCount int           `json:"completedTargetCount"`
+	CreatedAt            time.Time     `json:"createdAt"`
+	UpdatedAt            time.Time     `json:"updatedAt"`
 }
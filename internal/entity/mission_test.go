@@ -0,0 +1,77 @@
+package entity
+
+import "testing"
+
+func TestMissionStatusCanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from MissionStatus
+		to   MissionStatus
+		want bool
+	}{
+		{MissionStatusDraft, MissionStatusAssigned, true},
+		{MissionStatusDraft, MissionStatusAborted, true},
+		{MissionStatusDraft, MissionStatusInProgress, false},
+		{MissionStatusDraft, MissionStatusCompleted, false},
+		{MissionStatusDraft, MissionStatusDraft, false},
+
+		{MissionStatusAssigned, MissionStatusDraft, true},
+		{MissionStatusAssigned, MissionStatusInProgress, true},
+		{MissionStatusAssigned, MissionStatusAborted, true},
+		{MissionStatusAssigned, MissionStatusCompleted, false},
+
+		// unassigning a spy cat from a mission in progress moves it back to draft
+		{MissionStatusInProgress, MissionStatusDraft, true},
+		{MissionStatusInProgress, MissionStatusCompleted, true},
+		{MissionStatusInProgress, MissionStatusAborted, true},
+		{MissionStatusInProgress, MissionStatusAssigned, false},
+
+		{MissionStatusCompleted, MissionStatusDraft, false},
+		{MissionStatusCompleted, MissionStatusInProgress, false},
+		{MissionStatusCompleted, MissionStatusAborted, false},
+		{MissionStatusAborted, MissionStatusDraft, false},
+		{MissionStatusAborted, MissionStatusCompleted, false},
+
+		{MissionStatus("unknown"), MissionStatusDraft, false},
+		{MissionStatusDraft, MissionStatus("unknown"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+				t.Errorf("CanTransitionTo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMissionStatusIsValid(t *testing.T) {
+	for _, status := range []MissionStatus{
+		MissionStatusDraft, MissionStatusAssigned, MissionStatusInProgress, MissionStatusCompleted, MissionStatusAborted,
+	} {
+		if !status.IsValid() {
+			t.Errorf("%s.IsValid() = false, want true", status)
+		}
+	}
+	if MissionStatus("done").IsValid() {
+		t.Error(`"done".IsValid() = true, want false`)
+	}
+}
+
+func TestMissionStatusIsFinal(t *testing.T) {
+	tests := map[MissionStatus]bool{
+		MissionStatusDraft:      false,
+		MissionStatusAssigned:   false,
+		MissionStatusInProgress: false,
+		MissionStatusCompleted:  true,
+		MissionStatusAborted:    true,
+	}
+	for status, want := range tests {
+		if got := status.IsFinal(); got != want {
+			t.Errorf("%s.IsFinal() = %v, want %v", status, got, want)
+		}
+		// final statuses have no transitions
+		if want && len(missionTransitions[status]) != 0 {
+			t.Errorf("final status %s has transitions %v", status, missionTransitions[status])
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package entity
+
+// Role defines what a principal is allowed to do.
+type Role string
+
+const (
+	// RoleAdmin manages everything, including salaries, payroll and breeds.
+	RoleAdmin Role = "admin"
+	// RoleHandler runs spy cats and their missions.
+	RoleHandler Role = "handler"
+	// RoleAgent is a spy cat working on its missions.
+	RoleAgent Role = "agent"
+)
+
+// IsValid reports whether r is a known role.
+func (r Role) IsValid() bool {
+	switch r {
+	case RoleAdmin, RoleHandler, RoleAgent:
+		return true
+	default:
+		return false
+	}
+}
+
+// PrincipalKind defines how a principal is authenticated.
+type PrincipalKind string
+
+const (
+	// PrincipalUser is authenticated by a JWT and has a role.
+	PrincipalUser PrincipalKind = "user"
+	// PrincipalAPIKey is authenticated by an API key and has scopes.
+	PrincipalAPIKey PrincipalKind = "api_key"
+)
+
+// Principal is the authenticated caller. ID of an agent is its spy cat ID,
+// ID of an API key principal is the key ID.
+type Principal struct {
+	ID     string        `json:"id"`
+	Kind   PrincipalKind `json:"kind"`
+	Role   Role          `json:"role,omitempty"`
+	Scopes []Scope       `json:"scopes,omitempty"`
+}
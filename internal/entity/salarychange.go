@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// SalaryChange represents an entry of a spy cat salary history.
+// A change with AppliedAt unset is scheduled for its EffectiveAt date.
+type SalaryChange struct {
+	ID          string    `json:"id,omitempty" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	SpyCatID    string    `json:"spyCatId" gorm:"type:uuid;not null;index"`
+	OldSalary   float64   `json:"oldSalary"`
+	NewSalary   float64   `json:"newSalary"`
+	EffectiveAt time.Time `json:"effectiveAt" gorm:"not null;index"`
+	Reason      string    `json:"reason"`
+	// Actor is the ID of the principal that made the change.
+	Actor     string     `json:"actor"`
+	AppliedAt *time.Time `json:"appliedAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt,omitempty"`
+}
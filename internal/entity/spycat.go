@@ -12,10 +12,19 @@ type SpyCat struct {
 	Name              string         `json:"name" binding:"required"`
 	YearsOfExperience int            `json:"yearsOfExperience" binding:"required,gt=0"`
 	Breed             string         `json:"breed" binding:"required"`
+	BreedID           *string        `json:"breedId,omitempty"`
+	BreedProfile      BreedProfile   `json:"breedProfile" gorm:"embedded;embeddedPrefix:breed_"`
 	Salary            float64        `json:"salary" binding:"required,gt=0"`
 	MissionID         *string        `json:"missionId,omitempty" gorm:"type:uuid"`
 	Mission           *Mission       `json:"mission,omitempty"`
 	CreatedAt         time.Time      `json:"createdAt,omitempty" gorm:"index"`
 	UpdatedAt         time.Time      `json:"updatedAt,omitempty"`
 	DeletedAt         gorm.DeletedAt `json:"deletedAt,omitempty" gorm:"index"`
-} 
+}
+
+// BreedProfile is the breed metadata captured on a spy cat, so dossiers do not depend on TheCatAPI.
+type BreedProfile struct {
+	Origin      string `json:"origin"`
+	Temperament string `json:"temperament"`
+	Description string `json:"description"`
+}
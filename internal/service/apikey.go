@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Kontentski/develops-today-task/internal/entity"
+)
+
+const (
+	// apiKeyTag starts every API key, keys look like dtk_<prefix>_<secret>.
+	apiKeyTag = "dtk"
+	// apiKeyTouchInterval limits how often the last used time of a key is written.
+	apiKeyTouchInterval = time.Minute
+)
+
+type apiKeyService struct {
+	serviceContext
+}
+
+func NewAPIKeyService(options Options) APIKeyService {
+	return &apiKeyService{
+		serviceContext: serviceContext{
+			storages: options.Storages,
+			cfg:      options.Config,
+			apis:     options.APIs,
+			logger:   options.Logger.Named("APIKeyService"),
+		},
+	}
+}
+
+type CreateAPIKeyOptions struct {
+	Name      string
+	Scopes    []entity.Scope
+	ExpiresAt *time.Time
+}
+
+// CreatedAPIKey is a new API key with its plaintext, which is not stored and cannot be shown again.
+type CreatedAPIKey struct {
+	entity.APIKey
+	Key string `json:"key"`
+}
+
+func (s *apiKeyService) CreateAPIKey(ctx context.Context, opts CreateAPIKeyOptions) (*CreatedAPIKey, error) {
+	logger := s.loggerFor(ctx)
+	logger.Info("Creating api key", "name", opts.Name, "scopes", opts.Scopes, "expiresAt", opts.ExpiresAt)
+
+	for _, scope := range opts.Scopes {
+		if !scope.IsValid() {
+			return nil, fmt.Errorf("%w: %s", ErrCreateAPIKeyInvalidScope, scope)
+		}
+	}
+	if opts.ExpiresAt != nil && !opts.ExpiresAt.After(time.Now()) {
+		return nil, ErrCreateAPIKeyExpiryNotInFuture
+	}
+
+	prefix, secret, err := generateAPIKey()
+	if err != nil {
+		logger.Error("Failed to generate api key", "err", err)
+		return nil, err
+	}
+	plaintext := apiKeyTag + "_" + prefix + "_" + secret
+
+	key := &entity.APIKey{
+		Name:      opts.Name,
+		Prefix:    prefix,
+		Hash:      hashAPIKey(plaintext),
+		Scopes:    opts.Scopes,
+		ExpiresAt: opts.ExpiresAt,
+		CreatedBy: principalID(ctx),
+	}
+
+	createdKey, err := s.storages.APIKey.CreateAPIKey(ctx, key)
+	if err != nil {
+		logger.Error("Failed to create api key", "err", err)
+		return nil, err
+	}
+
+	logger.Info("Api key created successfully", "id", createdKey.ID, "prefix", createdKey.Prefix)
+	return &CreatedAPIKey{APIKey: *createdKey, Key: plaintext}, nil
+}
+
+func (s *apiKeyService) ListAPIKeys(ctx context.Context) ([]entity.APIKey, error) {
+	logger := s.loggerFor(ctx)
+	logger.Info("Listing api keys")
+
+	keys, err := s.storages.APIKey.ListAPIKeys(ctx)
+	if err != nil {
+		logger.Error("Failed to list api keys", "err", err)
+		return nil, err
+	}
+
+	logger.Info("Api keys listed successfully", "count", len(keys))
+	return keys, nil
+}
+
+func (s *apiKeyService) RevokeAPIKey(ctx context.Context, id string) (*entity.APIKey, error) {
+	logger := s.loggerFor(ctx)
+	logger.Info("Revoking api key", "id", id)
+
+	var revokedKey *entity.APIKey
+	err := s.storages.WithTx(ctx, func(tx Storages) error {
+		key, err := tx.APIKey.GetAPIKey(ctx, id)
+		if err != nil {
+			logger.Error("Failed to get api key", "err", err)
+			return err
+		}
+		if key == nil {
+			return ErrRevokeAPIKeyNotFound
+		}
+		if key.RevokedAt != nil {
+			return ErrRevokeAPIKeyRevoked
+		}
+
+		now := time.Now()
+		key.RevokedAt = &now
+		revokedKey, err = tx.APIKey.UpdateAPIKey(ctx, key)
+		if err != nil {
+			logger.Error("Failed to update api key", "err", err)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Api key revoked successfully", "id", id)
+	return revokedKey, nil
+}
+
+// AuthenticateAPIKey returns the principal of an active key and records its use.
+func (s *apiKeyService) AuthenticateAPIKey(ctx context.Context, plaintext string) (*entity.Principal, error) {
+	logger := s.loggerFor(ctx)
+
+	tag, rest, _ := strings.Cut(plaintext, "_")
+	prefix, _, _ := strings.Cut(rest, "_")
+	if tag != apiKeyTag || prefix == "" {
+		return nil, ErrAuthenticateAPIKeyInvalid
+	}
+
+	key, err := s.storages.APIKey.GetAPIKeyByPrefix(ctx, prefix)
+	if err != nil {
+		logger.Error("Failed to get api key", "err", err)
+		return nil, err
+	}
+
+	now := time.Now()
+	if key == nil || subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hashAPIKey(plaintext))) != 1 {
+		logger.Info("Unknown api key", "prefix", prefix)
+		return nil, ErrAuthenticateAPIKeyInvalid
+	}
+	if !key.IsActive(now) {
+		logger.Info("Inactive api key", "id", key.ID)
+		return nil, ErrAuthenticateAPIKeyInvalid
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		// the key is valid even if its use is not recorded
+		if err := s.storages.APIKey.TouchAPIKey(ctx, key.ID, now); err != nil {
+			logger.Warn("Failed to touch api key", "id", key.ID, "err", err)
+		}
+	}
+
+	return &entity.Principal{
+		ID:     key.ID,
+		Kind:   entity.PrincipalAPIKey,
+		Scopes: key.Scopes,
+	}, nil
+}
+
+// generateAPIKey returns random public prefix and secret of a new key.
+func generateAPIKey() (prefix, secret string, err error) {
+	b := make([]byte, 6+32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	return hex.EncodeToString(b[:6]), base64.RawURLEncoding.EncodeToString(b[6:]), nil
+}
+
+// hashAPIKey returns the stored hash of the key. Keys are random,
+// so a fast hash is enough to make leaked hashes useless.
+func hashAPIKey(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,6 +1,10 @@
 package service
 
-import "github.com/Kontentski/develops-today-task/internal/api/cat"
+import (
+	"context"
+
+	"github.com/Kontentski/develops-today-task/internal/api/cat"
+)
 
 // APIs provides a collection of API interfaces.
 type APIs struct {
@@ -8,5 +12,11 @@ type APIs struct {
 }
 
 type CatAPI interface {
-	GetBreeds() ([]cat.Breed, error)
+	GetBreeds(ctx context.Context) ([]cat.Breed, error)
+	// Refresh fetches breeds bypassing the cache, cached breeds are kept if it fails.
+	Refresh(ctx context.Context) error
+	// Quota returns the remaining request quota, nil if it is unknown.
+	Quota() *cat.Quota
+	// Stats describes the cache, FetchedAt is nil while the bundled snapshot is served.
+	Stats() cat.CatalogStats
 }
@@ -0,0 +1,372 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Kontentski/develops-today-task/internal/api/cat"
+	"github.com/Kontentski/develops-today-task/internal/entity"
+)
+
+type breedService struct {
+	serviceContext
+}
+
+func NewBreedService(options Options) BreedService {
+	return &breedService{
+		serviceContext: serviceContext{
+			storages: options.Storages,
+			cfg:      options.Config,
+			apis:     options.APIs,
+			logger:   options.Logger.Named("BreedService"),
+		},
+	}
+}
+
+// SyncBreeds stores breeds of the configured providers and records the sync.
+// Custom breeds are kept unless a provider has a breed with the same ID.
+// Until a provider responds the bundled snapshot is stored, so a fresh database is seeded,
+// but no sync is recorded and nil is returned.
+func (s *breedService) SyncBreeds(ctx context.Context) (*entity.BreedSync, error) {
+	logger := s.loggerFor(ctx)
+	logger.Info("Syncing breeds")
+
+	// checked before getting breeds, fetched breeds are never replaced by the snapshot
+	fromProvider := s.apis.CatAPI.Stats().FetchedAt != nil
+
+	fetched, err := s.apis.CatAPI.GetBreeds(ctx)
+	if err != nil {
+		logger.Error("Failed to get breeds", "err", err)
+		return nil, err
+	}
+
+	breeds := make([]entity.Breed, len(fetched))
+	for i, b := range fetched {
+		breeds[i] = entity.Breed{
+			ID:          b.ID,
+			Name:        b.Name,
+			Origin:      b.Origin,
+			Temperament: b.Temperament,
+			Description: b.Description,
+		}
+	}
+
+	var sync *entity.BreedSync
+	err = s.storages.WithTx(ctx, func(tx Storages) error {
+		if err := tx.Breed.UpsertBreeds(ctx, breeds); err != nil {
+			logger.Error("Failed to upsert breeds", "err", err)
+			return err
+		}
+		if !fromProvider {
+			return nil
+		}
+
+		var err error
+		sync, err = tx.Breed.CreateBreedSync(ctx, &entity.BreedSync{
+			Count:    len(breeds),
+			SyncedAt: time.Now(),
+		})
+		if err != nil {
+			logger.Error("Failed to create breed sync", "err", err)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if sync == nil {
+		logger.Info("Breeds seeded from the bundled snapshot", "count", len(breeds))
+		return nil, nil
+	}
+
+	logger.Info("Breeds synced successfully", "sync", sync)
+	return sync, nil
+}
+
+// BreedProfilesRefresh is the result of RefreshBreedProfiles.
+type BreedProfilesRefresh struct {
+	// Sync is nil if breeds could not be synced from a provider and stored breeds were used.
+	Sync        *entity.BreedSync `json:"sync"`
+	UpdatedCats int               `json:"updatedCats"`
+}
+
+// RefreshBreedProfiles syncs breeds and copies their profiles to existing spy cats.
+// Spy cats created before breeds were resolved get linked to breeds by name.
+func (s *breedService) RefreshBreedProfiles(ctx context.Context) (*BreedProfilesRefresh, error) {
+	logger := s.loggerFor(ctx)
+	logger.Info("Refreshing spy cats breed profiles")
+
+	// re-pull breeds, failures are logged by the cache which keeps serving cached breeds
+	if err := s.apis.CatAPI.Refresh(ctx); err != nil && ctx.Err() != nil {
+		return nil, err
+	}
+
+	refresh := &BreedProfilesRefresh{}
+	sync, err := s.SyncBreeds(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		logger.Warn("Failed to sync breeds, refreshing from stored breeds", "err", err)
+	}
+	refresh.Sync = sync
+
+	err = s.storages.WithTx(ctx, func(tx Storages) error {
+		breeds, err := tx.Breed.ListBreeds(ctx, ListBreedsOptions{})
+		if err != nil {
+			logger.Error("Failed to list breeds", "err", err)
+			return err
+		}
+
+		refresh.UpdatedCats = 0
+		for i := range breeds {
+			updated, err := tx.SpyCat.UpdateSpyCatsBreed(ctx, &breeds[i])
+			if err != nil {
+				logger.Error("Failed to update spy cats breed", "err", err)
+				return err
+			}
+			refresh.UpdatedCats += updated
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Spy cats breed profiles refreshed successfully", "refresh", refresh)
+	return refresh, nil
+}
+
+func (s *breedService) GetLastBreedSync(ctx context.Context) (*entity.BreedSync, error) {
+	logger := s.loggerFor(ctx)
+	logger.Info("Fetching last breed sync")
+
+	sync, err := s.storages.Breed.GetLastBreedSync(ctx)
+	if err != nil {
+		logger.Error("Failed to get last breed sync", "err", err)
+		return nil, err
+	}
+	if sync == nil {
+		return nil, ErrGetBreedSyncNotFound
+	}
+
+	logger.Info("Last breed sync fetched successfully", "sync", sync)
+	return sync, nil
+}
+
+// ListBreedsOptions is used to search breeds, filters match any part of the value in any case.
+type ListBreedsOptions struct {
+	Name   string
+	Origin string
+}
+
+// ListBreeds returns breeds accepted on spy cat creation.
+func (s *breedService) ListBreeds(ctx context.Context, opts ListBreedsOptions) ([]entity.Breed, error) {
+	logger := s.loggerFor(ctx)
+	logger.Info("Listing breeds", "opts", opts)
+
+	breeds, err := s.storages.Breed.ListBreeds(ctx, opts)
+	if err != nil {
+		logger.Error("Failed to list breeds", "err", err)
+		return nil, err
+	}
+
+	logger.Info("Breeds listed successfully", "count", len(breeds))
+	return breeds, nil
+}
+
+// GetBreedStats reports spy cats of the breed and how successful their missions are.
+func (s *breedService) GetBreedStats(ctx context.Context, id string) (*entity.BreedStats, error) {
+	logger := s.loggerFor(ctx)
+	logger.Info("Fetching breed stats", "id", id)
+
+	breed, err := s.storages.Breed.GetBreed(ctx, id)
+	if err != nil {
+		logger.Error("Failed to get breed", "err", err)
+		return nil, err
+	}
+	if breed == nil {
+		return nil, ErrGetBreedStatsNotFound
+	}
+
+	stats, err := s.storages.Breed.GetBreedStats(ctx, id)
+	if err != nil {
+		logger.Error("Failed to get breed stats", "err", err)
+		return nil, err
+	}
+	if stats.FinishedMissions > 0 {
+		rate := float64(stats.CompletedMissions) / float64(stats.FinishedMissions)
+		stats.MissionCompletionRate = &rate
+	}
+
+	logger.Info("Breed stats fetched successfully", "stats", stats)
+	return stats, nil
+}
+
+// GetCatAPIQuota returns the remaining TheCatAPI quota reported by its last response.
+func (s *breedService) GetCatAPIQuota(ctx context.Context) (*cat.Quota, error) {
+	quota := s.apis.CatAPI.Quota()
+	if quota == nil {
+		return nil, ErrGetCatAPIQuotaUnknown
+	}
+	return quota, nil
+}
+
+type CreateBreedOptions struct {
+	// ID defaults to a slug of the name.
+	ID          string
+	Name        string
+	Origin      string
+	Temperament string
+	Description string
+}
+
+// CreateBreed adds a custom breed missing in TheCatAPI.
+func (s *breedService) CreateBreed(ctx context.Context, opts CreateBreedOptions) (*entity.Breed, error) {
+	logger := s.loggerFor(ctx)
+	logger.Info("Creating custom breed", "opts", opts)
+
+	id := opts.ID
+	if id == "" {
+		id = breedSlug(opts.Name)
+	}
+	if id == "" || id != breedSlug(id) {
+		return nil, ErrCreateBreedInvalidID
+	}
+
+	var createdBreed *entity.Breed
+	err := s.storages.WithTx(ctx, func(tx Storages) error {
+		breeds, err := tx.Breed.ListBreeds(ctx, ListBreedsOptions{})
+		if err != nil {
+			logger.Error("Failed to list breeds", "err", err)
+			return err
+		}
+		if findBreed(breeds, id) != nil || findBreed(breeds, opts.Name) != nil {
+			return ErrCreateBreedExists
+		}
+
+		createdBreed, err = tx.Breed.CreateBreed(ctx, &entity.Breed{
+			ID:          id,
+			Name:        strings.TrimSpace(opts.Name),
+			Origin:      opts.Origin,
+			Temperament: opts.Temperament,
+			Description: opts.Description,
+			Custom:      true,
+		})
+		if err != nil {
+			logger.Error("Failed to create breed", "err", err)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Custom breed created successfully", "breed", createdBreed)
+	return createdBreed, nil
+}
+
+// breedSlug converts the breed name to an ID, e.g. "Ocicat Mix" to "ocicat-mix".
+func breedSlug(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		default:
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+const (
+	// maxBreedSuggestions limits "did you mean" suggestions of an unknown breed.
+	maxBreedSuggestions = 3
+	// maxBreedSuggestionDistance is the largest edit distance of a suggested breed.
+	maxBreedSuggestionDistance = 3
+)
+
+// findBreed returns the breed with passed ID or name in any case, nil if there is none.
+func findBreed(breeds []entity.Breed, breed string) *entity.Breed {
+	breed = strings.TrimSpace(breed)
+	for i := range breeds {
+		if strings.EqualFold(breeds[i].ID, breed) || strings.EqualFold(breeds[i].Name, breed) {
+			return &breeds[i]
+		}
+	}
+	return nil
+}
+
+// suggestBreeds returns names of breeds closest to passed breed by edit distance.
+func suggestBreeds(breeds []entity.Breed, breed string) []string {
+	type suggestion struct {
+		name     string
+		distance int
+	}
+
+	breed = strings.ToLower(strings.TrimSpace(breed))
+	if breed == "" {
+		return nil
+	}
+
+	// short inputs are close to too many breeds
+	maxDistance := min(maxBreedSuggestionDistance, max(1, len([]rune(breed))/2))
+
+	var suggestions []suggestion
+	for _, b := range breeds {
+		name := strings.ToLower(b.Name)
+		distance := min(levenshtein(breed, name), levenshtein(breed, strings.ToLower(b.ID)))
+		if len(breed) >= 3 && strings.Contains(name, breed) {
+			// part of a longer name, e.g. "norwegian"
+			distance = min(distance, 1)
+		}
+		if distance <= maxDistance {
+			suggestions = append(suggestions, suggestion{name: b.Name, distance: distance})
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].distance < suggestions[j].distance
+	})
+
+	names := make([]string, 0, maxBreedSuggestions)
+	for i := 0; i < len(suggestions) && i < maxBreedSuggestions; i++ {
+		names = append(names, suggestions[i].name)
+	}
+	return names
+}
+
+// levenshtein returns the number of single rune edits turning a into b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
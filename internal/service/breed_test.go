@@ -0,0 +1,105 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Kontentski/develops-today-task/internal/entity"
+)
+
+var testBreeds = []entity.Breed{
+	{ID: "abys", Name: "Abyssinian"},
+	{ID: "beng", Name: "Bengal"},
+	{ID: "bomb", Name: "Bombay"},
+	{ID: "norw", Name: "Norwegian Forest Cat"},
+	{ID: "ocic", Name: "Ocicat"},
+	{ID: "siam", Name: "Siamese"},
+}
+
+func TestFindBreed(t *testing.T) {
+	tests := []struct {
+		name  string
+		breed string
+		want  string
+	}{
+		{name: "by id", breed: "beng", want: "beng"},
+		{name: "by name", breed: "Bengal", want: "beng"},
+		{name: "any case", breed: "SIAMESE", want: "siam"},
+		{name: "surrounding spaces", breed: "  Ocicat ", want: "ocic"},
+		{name: "unknown", breed: "Sphynx"},
+		{name: "part of a name", breed: "Norwegian"},
+		{name: "empty", breed: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findBreed(testBreeds, tt.breed)
+			if tt.want == "" {
+				if got != nil {
+					t.Fatalf("findBreed(%q) = %q, want nil", tt.breed, got.ID)
+				}
+				return
+			}
+			if got == nil || got.ID != tt.want {
+				t.Fatalf("findBreed(%q) = %v, want %q", tt.breed, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuggestBreeds(t *testing.T) {
+	tests := []struct {
+		name  string
+		breed string
+		want  []string
+	}{
+		{name: "typo", breed: "Bengol", want: []string{"Bengal"}},
+		{name: "closest first", breed: "Bengay", want: []string{"Bengal", "Bombay"}},
+		{name: "typo in id", breed: "siem", want: []string{"Siamese"}},
+		{name: "part of a longer name", breed: "norwegian", want: []string{"Norwegian Forest Cat"}},
+		{name: "short input", breed: "xy", want: []string{}},
+		{name: "nothing close", breed: "Sphynx", want: []string{}},
+		{name: "empty", breed: "  "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := suggestBreeds(testBreeds, tt.breed); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("suggestBreeds(%q) = %q, want %q", tt.breed, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuggestBreedsLimit(t *testing.T) {
+	breeds := []entity.Breed{
+		{ID: "a", Name: "Cat"},
+		{ID: "b", Name: "Bat"},
+		{ID: "c", Name: "Hat"},
+		{ID: "d", Name: "Rat"},
+	}
+	if got := suggestBreeds(breeds, "mat"); len(got) != maxBreedSuggestions {
+		t.Fatalf("suggestBreeds() = %q, want %d suggestions", got, maxBreedSuggestions)
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "", b: "", want: 0},
+		{a: "", b: "cat", want: 3},
+		{a: "cat", b: "", want: 3},
+		{a: "cat", b: "cat", want: 0},
+		{a: "cat", b: "cut", want: 1},
+		{a: "cat", b: "cats", want: 1},
+		{a: "cats", b: "cat", want: 1},
+		{a: "kitten", b: "sitting", want: 3},
+		{a: "flaw", b: "lawn", want: 2},
+		{a: "чай", b: "чаи", want: 1},
+	}
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Kontentski/develops-today-task/internal/entity"
+)
+
+// idempotencyKeyMaxLength limits keys sent by clients.
+const idempotencyKeyMaxLength = 255
+
+type idempotencyService struct {
+	serviceContext
+}
+
+func NewIdempotencyService(options Options) IdempotencyService {
+	return &idempotencyService{
+		serviceContext: serviceContext{
+			storages: options.Storages,
+			cfg:      options.Config,
+			apis:     options.APIs,
+			logger:   options.Logger.Named("IdempotencyService"),
+		},
+	}
+}
+
+type BeginIdempotentRequestOptions struct {
+	Key string
+	// Fingerprint identifies the request, the key cannot be reused for another one.
+	Fingerprint string
+}
+
+type CompleteIdempotentRequestOptions struct {
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+func (s *idempotencyService) BeginIdempotentRequest(ctx context.Context, opts BeginIdempotentRequestOptions) (*entity.IdempotencyKey, error) {
+	logger := s.loggerFor(ctx)
+	logger.Info("Beginning idempotent request", "key", opts.Key)
+
+	if opts.Key == "" || len(opts.Key) > idempotencyKeyMaxLength {
+		return nil, ErrBeginIdempotentRequestInvalidKey
+	}
+	owner := idempotencyKeyOwner(ctx)
+
+	key, err := s.storages.Idempotency.GetIdempotencyKey(ctx, owner, opts.Key)
+	if err != nil {
+		logger.Error("Failed to get idempotency key", "err", err)
+		return nil, err
+	}
+
+	now := time.Now()
+	if key != nil && (!now.Before(key.ExpiresAt) || isAbandonedIdempotencyKey(key, now, s.cfg.Idempotency.LockTimeout)) {
+		// expired keys are purged periodically, until then they are replaced on use like keys
+		// of requests that crashed before releasing them
+		logger.Info("Replacing expired or abandoned idempotency key", "id", key.ID)
+		if err := s.storages.Idempotency.DeleteIdempotencyKey(ctx, key.ID); err != nil {
+			logger.Error("Failed to delete idempotency key", "err", err)
+			return nil, err
+		}
+		key = nil
+	}
+
+	if key == nil {
+		key, err = s.storages.Idempotency.CreateIdempotencyKey(ctx, &entity.IdempotencyKey{
+			Owner:       owner,
+			Key:         opts.Key,
+			Fingerprint: opts.Fingerprint,
+			ExpiresAt:   now.Add(s.cfg.Idempotency.TTL),
+		})
+		if err != nil {
+			logger.Error("Failed to create idempotency key", "err", err)
+			return nil, err
+		}
+		if key != nil {
+			logger.Info("Idempotency key claimed", "id", key.ID)
+			return key, nil
+		}
+
+		// a concurrent request claimed the key first
+		key, err = s.storages.Idempotency.GetIdempotencyKey(ctx, owner, opts.Key)
+		if err != nil {
+			logger.Error("Failed to get idempotency key", "err", err)
+			return nil, err
+		}
+		if key == nil {
+			return nil, ErrBeginIdempotentRequestInProgress
+		}
+	}
+
+	if key.Fingerprint != opts.Fingerprint {
+		logger.Info("Idempotency key reused with a different request", "id", key.ID)
+		return nil, ErrBeginIdempotentRequestMismatch
+	}
+	if !key.IsCompleted() {
+		return nil, ErrBeginIdempotentRequestInProgress
+	}
+
+	logger.Info("Replaying idempotent request", "id", key.ID, "status", key.ResponseStatus)
+	return key, nil
+}
+
+func (s *idempotencyService) CompleteIdempotentRequest(ctx context.Context, key *entity.IdempotencyKey, opts CompleteIdempotentRequestOptions) error {
+	logger := s.loggerFor(ctx)
+	logger.Info("Completing idempotent request", "id", key.ID, "status", opts.Status)
+
+	key.ResponseStatus = opts.Status
+	key.ResponseContentType = opts.ContentType
+	key.ResponseBody = opts.Body
+	if _, err := s.storages.Idempotency.UpdateIdempotencyKey(ctx, key); err != nil {
+		logger.Error("Failed to update idempotency key", "err", err)
+		return err
+	}
+	return nil
+}
+
+func (s *idempotencyService) ReleaseIdempotentRequest(ctx context.Context, key *entity.IdempotencyKey) error {
+	logger := s.loggerFor(ctx)
+	logger.Info("Releasing idempotency key", "id", key.ID)
+
+	if err := s.storages.Idempotency.DeleteIdempotencyKey(ctx, key.ID); err != nil {
+		logger.Error("Failed to delete idempotency key", "err", err)
+		return err
+	}
+	return nil
+}
+
+func (s *idempotencyService) PurgeExpiredIdempotencyKeys(ctx context.Context) (int, error) {
+	logger := s.loggerFor(ctx)
+
+	deleted, err := s.storages.Idempotency.DeleteExpiredIdempotencyKeys(ctx, time.Now())
+	if err != nil {
+		logger.Error("Failed to delete expired idempotency keys", "err", err)
+		return 0, err
+	}
+	if deleted > 0 {
+		logger.Info("Expired idempotency keys purged", "count", deleted)
+	}
+	return deleted, nil
+}
+
+// isAbandonedIdempotencyKey reports whether the request holding the key is in progress for longer than lockTimeout.
+func isAbandonedIdempotencyKey(key *entity.IdempotencyKey, now time.Time, lockTimeout time.Duration) bool {
+	return !key.IsCompleted() && now.Sub(key.CreatedAt) >= lockTimeout
+}
+
+// idempotencyKeyOwner identifies the principal of ctx, so clients cannot replay responses of each other.
+func idempotencyKeyOwner(ctx context.Context) string {
+	principal := PrincipalFromContext(ctx)
+	if principal == nil {
+		return ""
+	}
+	return string(principal.Kind) + ":" + principal.ID
+}
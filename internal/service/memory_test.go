@@ -0,0 +1,323 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Kontentski/develops-today-task/config"
+	"github.com/Kontentski/develops-today-task/internal/entity"
+	"github.com/Kontentski/develops-today-task/internal/service"
+	"github.com/Kontentski/develops-today-task/internal/storage/memory"
+	"github.com/Kontentski/develops-today-task/pkg/logging"
+)
+
+// newMemoryServices creates services backed by an in-memory store with a single known breed.
+func newMemoryServices(t *testing.T) (service.Services, service.Storages) {
+	t.Helper()
+
+	storages := memory.NewStorages(memory.NewStore())
+	err := storages.Breed.UpsertBreeds(context.Background(), []entity.Breed{{ID: "beng", Name: "Bengal"}})
+	if err != nil {
+		t.Fatalf("UpsertBreeds() error = %v", err)
+	}
+
+	options := service.Options{
+		Storages: storages,
+		Config:   &config.Config{},
+		Logger:   logging.NewZapLogger("error"),
+	}
+	return service.NewService(options), storages
+}
+
+func createSpyCat(t *testing.T, services service.Services, name string, salary float64) *entity.SpyCat {
+	t.Helper()
+
+	cat, err := services.SpyCat.CreateSpyCat(context.Background(), service.CreateSpyCatOptions{
+		Name:   name,
+		Breed:  "bengal",
+		Salary: salary,
+	})
+	if err != nil {
+		t.Fatalf("CreateSpyCat() error = %v", err)
+	}
+	return cat
+}
+
+func createMission(t *testing.T, services service.Services) *entity.Mission {
+	t.Helper()
+
+	mission, err := services.Mission.CreateMission(context.Background(), service.CreateMissionOptions{
+		Targets: []service.CreateTargetOptions{{Name: "Mouse", Country: "UA"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateMission() error = %v", err)
+	}
+	return mission
+}
+
+func TestMemoryAssignSpyCat(t *testing.T) {
+	ctx := context.Background()
+	services, _ := newMemoryServices(t)
+
+	cat := createSpyCat(t, services, "Tom", 100)
+	first := createMission(t, services)
+	second := createMission(t, services)
+
+	if err := services.Mission.AssignSpyCat(ctx, first.ID, service.AssignSpyCatOptions{SpyCatID: cat.ID}); err != nil {
+		t.Fatalf("AssignSpyCat() error = %v", err)
+	}
+	mission, err := services.Mission.GetMission(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("GetMission() error = %v", err)
+	}
+	if mission.Status != entity.MissionStatusAssigned || mission.SpyCatID == nil || *mission.SpyCatID != cat.ID {
+		t.Fatalf("assigned mission = %+v, want assigned to %s", mission, cat.ID)
+	}
+
+	err = services.Mission.AssignSpyCat(ctx, second.ID, service.AssignSpyCatOptions{SpyCatID: cat.ID})
+	if !errors.Is(err, service.ErrAssignSpyCatBusy) {
+		t.Fatalf("AssignSpyCat() of a busy cat error = %v, want %v", err, service.ErrAssignSpyCatBusy)
+	}
+	mission, err = services.Mission.GetMission(ctx, second.ID)
+	if err != nil {
+		t.Fatalf("GetMission() error = %v", err)
+	}
+	if mission.Status != entity.MissionStatusDraft || mission.SpyCatID != nil {
+		t.Fatalf("mission of the failed assignment = %+v, want unassigned draft", mission)
+	}
+
+	if err := services.Mission.UnassignSpyCat(ctx, first.ID, service.UnassignSpyCatOptions{}); err != nil {
+		t.Fatalf("UnassignSpyCat() error = %v", err)
+	}
+	if err := services.Mission.AssignSpyCat(ctx, second.ID, service.AssignSpyCatOptions{SpyCatID: cat.ID}); err != nil {
+		t.Fatalf("AssignSpyCat() of a released cat error = %v", err)
+	}
+
+	assignments, err := services.SpyCat.ListSpyCatAssignments(ctx, cat.ID)
+	if err != nil {
+		t.Fatalf("ListSpyCatAssignments() error = %v", err)
+	}
+	open := 0
+	for _, assignment := range assignments {
+		if assignment.EndedAt == nil {
+			open++
+		}
+	}
+	if len(assignments) != 2 || open != 1 {
+		t.Fatalf("ListSpyCatAssignments() = %+v, want 2 assignments with 1 open", assignments)
+	}
+}
+
+func TestMemoryAssignSpyCatConcurrently(t *testing.T) {
+	ctx := context.Background()
+	services, _ := newMemoryServices(t)
+
+	cat := createSpyCat(t, services, "Tom", 100)
+	missions := make([]*entity.Mission, 5)
+	for i := range missions {
+		missions[i] = createMission(t, services)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(missions))
+	for i, mission := range missions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = services.Mission.AssignSpyCat(ctx, mission.ID, service.AssignSpyCatOptions{SpyCatID: cat.ID})
+		}()
+	}
+	wg.Wait()
+
+	assigned := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			assigned++
+		case !errors.Is(err, service.ErrAssignSpyCatBusy):
+			t.Fatalf("AssignSpyCat() error = %v, want nil or %v", err, service.ErrAssignSpyCatBusy)
+		}
+	}
+	if assigned != 1 {
+		t.Fatalf("spy cat assigned to %d missions, want 1", assigned)
+	}
+}
+
+func TestMemoryRollbackKeepsOtherWrites(t *testing.T) {
+	ctx := context.Background()
+	services, storages := newMemoryServices(t)
+
+	errRollback := errors.New("rollback")
+	started := make(chan struct{})
+	done := make(chan error)
+
+	var txCat *entity.SpyCat
+	err := storages.WithTx(ctx, func(tx service.Storages) error {
+		var err error
+		txCat, err = tx.SpyCat.CreateSpyCat(ctx, &entity.SpyCat{Name: "Rolled back", Breed: "Bengal"})
+		if err != nil {
+			return err
+		}
+
+		// written outside of the transaction while it runs
+		go func() {
+			close(started)
+			_, err := storages.SpyCat.CreateSpyCat(ctx, &entity.SpyCat{Name: "Kept", Breed: "Bengal"})
+			done <- err
+		}()
+		<-started
+		time.Sleep(10 * time.Millisecond)
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("WithTx() error = %v, want %v", err, errRollback)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("CreateSpyCat() outside of the transaction error = %v", err)
+	}
+
+	got, err := storages.SpyCat.GetSpyCat(ctx, txCat.ID)
+	if err != nil {
+		t.Fatalf("GetSpyCat() error = %v", err)
+	}
+	if got != nil {
+		t.Fatalf("spy cat created in the rolled back transaction = %+v, want nil", got)
+	}
+
+	page, err := services.SpyCat.ListSpyCats(ctx, service.ListSpyCatsOptions{})
+	if err != nil {
+		t.Fatalf("ListSpyCats() error = %v", err)
+	}
+	if len(page.Items) != 1 || page.Items[0].Name != "Kept" {
+		t.Fatalf("ListSpyCats() = %+v, want only the spy cat created outside of the transaction", page.Items)
+	}
+}
+
+func TestMemoryApplyScheduledSalaryChanges(t *testing.T) {
+	ctx := service.WithPrincipal(context.Background(), &entity.Principal{ID: "boss", Role: entity.RoleAdmin})
+	services, _ := newMemoryServices(t)
+
+	cat := createSpyCat(t, services, "Tom", 100)
+	_, err := services.SpyCat.ScheduleSalaryChange(ctx, cat.ID, service.ScheduleSalaryChangeOptions{
+		Salary:      150,
+		EffectiveAt: time.Now().Add(20 * time.Millisecond),
+		Reason:      "promotion",
+	})
+	if err != nil {
+		t.Fatalf("ScheduleSalaryChange() error = %v", err)
+	}
+
+	if applied, err := services.SpyCat.ApplyScheduledSalaryChanges(ctx); err != nil || applied != 0 {
+		t.Fatalf("ApplyScheduledSalaryChanges() before the effective date = %d, %v, want 0, nil", applied, err)
+	}
+	time.Sleep(30 * time.Millisecond)
+
+	// every replica runs the job, the change must be applied once
+	var wg sync.WaitGroup
+	applied := make([]int, 3)
+	for i := range applied {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := services.SpyCat.ApplyScheduledSalaryChanges(ctx)
+			if err != nil {
+				t.Errorf("ApplyScheduledSalaryChanges() error = %v", err)
+			}
+			applied[i] = n
+		}()
+	}
+	wg.Wait()
+	if total := applied[0] + applied[1] + applied[2]; total != 1 {
+		t.Fatalf("ApplyScheduledSalaryChanges() applied %v changes, want 1 in total", applied)
+	}
+
+	got, err := services.SpyCat.GetSpyCat(ctx, cat.ID)
+	if err != nil {
+		t.Fatalf("GetSpyCat() error = %v", err)
+	}
+	if got.Salary != 150 {
+		t.Fatalf("spy cat salary = %v, want 150", got.Salary)
+	}
+
+	history, err := services.SpyCat.ListSalaryHistory(ctx, cat.ID)
+	if err != nil {
+		t.Fatalf("ListSalaryHistory() error = %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("ListSalaryHistory() = %+v, want initial and scheduled changes", history)
+	}
+	change := history[1]
+	if change.OldSalary != 100 || change.NewSalary != 150 || change.AppliedAt == nil || change.Actor != "boss" {
+		t.Fatalf("scheduled salary change = %+v, want applied 100 -> 150 by boss", change)
+	}
+}
+
+func TestMemoryUpdateTargetOfDeletedMission(t *testing.T) {
+	ctx := context.Background()
+	services, _ := newMemoryServices(t)
+
+	mission := createMission(t, services)
+	if err := services.Mission.DeleteMission(ctx, mission.ID); err != nil {
+		t.Fatalf("DeleteMission() error = %v", err)
+	}
+
+	notes := "seen at the market"
+	_, err := services.Target.UpdateTarget(ctx, mission.Targets[0].ID, service.UpdateTargetOptions{Notes: &notes})
+	if !errors.Is(err, service.ErrUpdateTargetNotFound) {
+		t.Fatalf("UpdateTarget() error = %v, want %v", err, service.ErrUpdateTargetNotFound)
+	}
+}
+
+func TestMemoryDeleteMissionWhileAssigning(t *testing.T) {
+	ctx := context.Background()
+	services, storages := newMemoryServices(t)
+
+	cat := createSpyCat(t, services, "Tom", 100)
+	for i := 0; i < 20; i++ {
+		mission := createMission(t, services)
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_ = services.Mission.AssignSpyCat(ctx, mission.ID, service.AssignSpyCatOptions{SpyCatID: cat.ID})
+		}()
+		go func() {
+			defer wg.Done()
+			_ = services.Mission.DeleteMission(ctx, mission.ID)
+		}()
+		wg.Wait()
+
+		// the cat is either free or assigned to a mission which still exists
+		got, err := storages.SpyCat.GetSpyCat(ctx, cat.ID)
+		if err != nil {
+			t.Fatalf("GetSpyCat() error = %v", err)
+		}
+		if got.MissionID == nil {
+			continue
+		}
+		assigned, err := storages.Mission.GetMission(ctx, *got.MissionID)
+		if err != nil {
+			t.Fatalf("GetMission() error = %v", err)
+		}
+		if assigned == nil {
+			t.Fatalf("spy cat is busy with deleted mission %s", *got.MissionID)
+		}
+		if err := services.Mission.UnassignSpyCat(ctx, assigned.ID, service.UnassignSpyCatOptions{}); err != nil {
+			t.Fatalf("UnassignSpyCat() error = %v", err)
+		}
+	}
+}
+
+func TestMemoryListMissionsInvalidStatus(t *testing.T) {
+	services, _ := newMemoryServices(t)
+
+	status := entity.MissionStatus("finished")
+	_, err := services.Mission.ListMissions(context.Background(), service.ListMissionsOptions{Status: &status})
+	if !errors.Is(err, service.ErrListMissionsInvalidStatus) {
+		t.Fatalf("ListMissions() error = %v, want %v", err, service.ErrListMissionsInvalidStatus)
+	}
+}
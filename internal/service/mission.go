@@ -2,9 +2,10 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/Kontentski/develops-today-task/internal/entity"
-	"github.com/Kontentski/develops-today-task/pkg/errs"
 )
 
 type missionService struct {
@@ -25,20 +26,20 @@ func NewMissionService(options Options, storage MissionStorage) MissionService {
 }
 
 type CreateMissionOptions struct {
-	Completed bool
-	Targets   []CreateTargetOptions
+	Targets []CreateTargetOptions
 }
 
 func (s *missionService) CreateMission(ctx context.Context, opts CreateMissionOptions) (*entity.Mission, error) {
-	s.logger.Info("Creating new mission", "opts", opts)
+	logger := s.loggerFor(ctx)
+	logger.Info("Creating new mission", "opts", opts)
 
 	if len(opts.Targets) == 0 || len(opts.Targets) > 3 {
 		return nil, ErrCreateMissionInvalidTargets
 	}
 
 	mission := &entity.Mission{
-		Completed: opts.Completed,
-		Targets:   make([]entity.Target, len(opts.Targets)),
+		Status:  entity.MissionStatusDraft,
+		Targets: make([]entity.Target, len(opts.Targets)),
 	}
 
 	// Create targets
@@ -53,133 +54,504 @@ func (s *missionService) CreateMission(ctx context.Context, opts CreateMissionOp
 
 	createdMission, err := s.storage.CreateMission(ctx, mission)
 	if err != nil {
-		s.logger.Error("Failed to create mission", "err", err)
+		logger.Error("Failed to create mission", "err", err)
 		return nil, err
 	}
 
-	s.logger.Info("Mission created successfully", "mission", createdMission)
+	logger.Info("Mission created successfully", "mission", createdMission)
 	return createdMission, nil
 }
 
 func (s *missionService) DeleteMission(ctx context.Context, id string) error {
-	s.logger.Info("Deleting mission", "id", id)
-
-	mission, err := s.storage.GetMission(ctx, id)
-	if err != nil {
-		s.logger.Error("Failed to get mission", "err", err)
-		return err
-	}
-	if mission == nil {
-		return ErrDeleteMissionNotFound
-	}
+	logger := s.loggerFor(ctx)
+	logger.Info("Deleting mission", "id", id)
+
+	err := s.storages.WithTx(ctx, func(tx Storages) error {
+		// lock the mission, so a spy cat is not assigned to it while it is deleted
+		mission, err := tx.Mission.GetMissionForUpdate(ctx, id)
+		if err != nil {
+			logger.Error("Failed to get mission", "err", err)
+			return err
+		}
+		if mission == nil {
+			return ErrDeleteMissionNotFound
+		}
 
-	if mission.SpyCatID != nil {
-		return ErrDeleteMissionAssigned
-	}
+		if mission.SpyCatID != nil {
+			return ErrDeleteMissionAssigned
+		}
 
-	if err := s.storage.DeleteMission(ctx, id); err != nil {
-		s.logger.Error("Failed to delete mission", "err", err)
+		if err := tx.Mission.DeleteMission(ctx, id); err != nil {
+			logger.Error("Failed to delete mission", "err", err)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
 		return err
 	}
 
-	s.logger.Info("Mission deleted successfully", "id", id)
+	logger.Info("Mission deleted successfully", "id", id)
 	return nil
 }
 
 func (s *missionService) GetMission(ctx context.Context, id string) (*entity.Mission, error) {
-	s.logger.Info("Fetching mission", "id", id)
+	logger := s.loggerFor(ctx)
+	logger.Info("Fetching mission", "id", id)
 
 	mission, err := s.storage.GetMission(ctx, id)
 	if err != nil {
-		s.logger.Error("Failed to get mission", "err", err)
+		logger.Error("Failed to get mission", "err", err)
 		return nil, err
 	}
 	if mission == nil {
 		return nil, ErrGetMissionNotFound
 	}
+	if !canAccessMission(ctx, mission) {
+		logger.Info("Mission is not assigned to the agent", "id", id)
+		return nil, ErrGetMissionForbidden
+	}
 
-	s.logger.Info("Mission fetched successfully", "mission", mission)
+	logger.Info("Mission fetched successfully", "mission", mission)
 	return mission, nil
 }
 
-type UpdateMissionOptions struct {
-	Completed bool
+type TransitionMissionOptions struct {
+	Status entity.MissionStatus
 }
 
-func (s *missionService) UpdateMission(ctx context.Context, id string, opts UpdateMissionOptions) (*entity.Mission, error) {
-	s.logger.Info("Updating mission", "id", id, "opts", opts)
+// TransitionMission moves the mission to the next lifecycle status.
+// Assigned and draft statuses are reached only by assigning and unassigning spy cats.
+func (s *missionService) TransitionMission(ctx context.Context, id string, opts TransitionMissionOptions) (*entity.Mission, error) {
+	logger := s.loggerFor(ctx)
+	logger.Info("Transitioning mission", "id", id, "opts", opts)
 
-	mission, err := s.storage.GetMission(ctx, id)
+	if !opts.Status.IsValid() {
+		return nil, ErrTransitionMissionUnknownStatus
+	}
+	if opts.Status == entity.MissionStatusDraft || opts.Status == entity.MissionStatusAssigned {
+		return nil, ErrTransitionMissionAssignment
+	}
+
+	var mission *entity.Mission
+	err := s.storages.WithTx(ctx, func(tx Storages) error {
+		var err error
+		mission, err = tx.Mission.GetMissionForUpdate(ctx, id)
+		if err != nil {
+			logger.Error("Failed to get mission", "err", err)
+			return err
+		}
+		if mission == nil {
+			return ErrTransitionMissionNotFound
+		}
+
+		if !mission.Status.CanTransitionTo(opts.Status) {
+			return fmt.Errorf("%w from %s to %s", ErrTransitionMissionNotAllowed, mission.Status, opts.Status)
+		}
+
+		if opts.Status == entity.MissionStatusCompleted {
+			for _, target := range mission.Targets {
+				if !target.Completed {
+					return ErrTransitionMissionTargetsIncomplete
+				}
+			}
+		}
+
+		if err := finishMission(ctx, tx, mission, opts.Status); err != nil {
+			logger.Error("Failed to finish mission", "err", err)
+			return err
+		}
+		return nil
+	})
 	if err != nil {
-		s.logger.Error("Failed to get mission", "err", err)
 		return nil, err
 	}
-	if mission == nil {
-		return nil, errs.New("mission not found")
+
+	logger.Info("Mission transitioned successfully", "mission", mission)
+	return mission, nil
+}
+
+// missionSortKey is the only sort order of missions: newest first.
+const missionSortKey = "-createdAt"
+
+// ListMissionsOptions is used to filter and paginate missions.
+type ListMissionsOptions struct {
+	Status        *entity.MissionStatus
+	SpyCatID      string
+	Unassigned    bool
+	TargetCountry string
+	CreatedFrom   *time.Time
+	CreatedTo     *time.Time
+	// IncludeTargets and IncludeSpyCat preload relations of full missions.
+	IncludeTargets bool
+	IncludeSpyCat  bool
+	Cursor         *Cursor
+	Limit          int
+}
+
+// MissionPage is a single page of missions.
+type MissionPage struct {
+	Items      []entity.Mission `json:"items"`
+	NextCursor string           `json:"nextCursor,omitempty"`
+}
+
+// MissionSummaryPage is a single page of mission summaries.
+type MissionSummaryPage struct {
+	Items      []entity.MissionSummary `json:"items"`
+	NextCursor string                  `json:"nextCursor,omitempty"`
+}
+
+// missionCursor returns cursor pointing after the mission with passed ID and creation time.
+func missionCursor(id string, createdAt time.Time) string {
+	return Cursor{
+		Sort:  missionSortKey,
+		Value: createdAt.UTC().Format(time.RFC3339Nano),
+		ID:    id,
+	}.Encode()
+}
+
+// validateListMissionsOptions validates options and returns normalized page limit.
+func validateListMissionsOptions(opts ListMissionsOptions) (int, error) {
+	if opts.Status != nil && !opts.Status.IsValid() {
+		return 0, ErrListMissionsInvalidStatus
+	}
+	if opts.Unassigned && opts.SpyCatID != "" {
+		return 0, ErrListMissionsAssignmentConflict
+	}
+	if opts.CreatedFrom != nil && opts.CreatedTo != nil && opts.CreatedFrom.After(*opts.CreatedTo) {
+		return 0, ErrListMissionsInvalidCreatedRange
+	}
+	if opts.Cursor != nil {
+		if opts.Cursor.Sort != missionSortKey {
+			return 0, ErrInvalidCursor
+		}
+		if _, err := time.Parse(time.RFC3339Nano, opts.Cursor.Value); err != nil {
+			return 0, ErrInvalidCursor
+		}
+	}
+	return pageLimit(opts.Limit), nil
+}
+
+func (s *missionService) ListMissions(ctx context.Context, opts ListMissionsOptions) (*MissionPage, error) {
+	logger := s.loggerFor(ctx)
+	logger.Info("Listing missions", "opts", opts)
+
+	limit, err := validateListMissionsOptions(opts)
+	if err != nil {
+		return nil, err
 	}
 
-	mission.Completed = opts.Completed
-	updatedMission, err := s.storage.UpdateMission(ctx, mission)
+	// fetch one extra row to know if there is a next page
+	opts.Limit = limit + 1
+	missions, err := s.storage.ListMissions(ctx, opts)
 	if err != nil {
-		s.logger.Error("Failed to update mission", "err", err)
+		logger.Error("Failed to list missions", "err", err)
 		return nil, err
 	}
 
-	s.logger.Info("Mission updated successfully", "mission", updatedMission)
-	return updatedMission, nil
+	page := &MissionPage{Items: missions}
+	if len(missions) > limit {
+		page.Items = missions[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = missionCursor(last.ID, last.CreatedAt)
+	}
+
+	logger.Info("Missions listed successfully", "count", len(page.Items))
+	return page, nil
 }
 
-func (s *missionService) ListMissions(ctx context.Context) ([]entity.Mission, error) {
-	s.logger.Info("Listing all missions")
+func (s *missionService) ListMissionSummaries(ctx context.Context, opts ListMissionsOptions) (*MissionSummaryPage, error) {
+	logger := s.loggerFor(ctx)
+	logger.Info("Listing mission summaries", "opts", opts)
+
+	limit, err := validateListMissionsOptions(opts)
+	if err != nil {
+		return nil, err
+	}
 
-	missions, err := s.storage.ListMissions(ctx)
+	// fetch one extra row to know if there is a next page
+	opts.Limit = limit + 1
+	summaries, err := s.storage.ListMissionSummaries(ctx, opts)
 	if err != nil {
-		s.logger.Error("Failed to list missions", "err", err)
+		logger.Error("Failed to list mission summaries", "err", err)
 		return nil, err
 	}
 
-	s.logger.Info("Missions listed successfully", "count", len(missions))
-	return missions, nil
+	page := &MissionSummaryPage{Items: summaries}
+	if len(summaries) > limit {
+		page.Items = summaries[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = missionCursor(last.ID, last.CreatedAt)
+	}
+
+	logger.Info("Mission summaries listed successfully", "count", len(page.Items))
+	return page, nil
 }
 
-func (s *missionService) AssignSpyCat(ctx context.Context, missionID, spyCatID string) error {
-	s.logger.Info("Assigning spy cat to mission", "missionID", missionID, "spyCatID", spyCatID)
+type AssignSpyCatOptions struct {
+	SpyCatID string
+	Reason   string
+}
 
-	// Get mission
-	mission, err := s.storage.GetMission(ctx, missionID)
+func (s *missionService) AssignSpyCat(ctx context.Context, missionID string, opts AssignSpyCatOptions) error {
+	logger := s.loggerFor(ctx)
+	logger.Info("Assigning spy cat to mission", "missionID", missionID, "opts", opts)
+
+	err := s.storages.WithTx(ctx, func(tx Storages) error {
+		// Get mission
+		mission, err := tx.Mission.GetMissionForUpdate(ctx, missionID)
+		if err != nil {
+			logger.Error("Failed to get mission", "err", err)
+			return err
+		}
+		if mission == nil {
+			return ErrAssignMissionNotFound
+		}
+
+		if mission.SpyCatID != nil {
+			return ErrAssignMissionHasCat
+		}
+
+		if mission.Status != entity.MissionStatusDraft {
+			return ErrAssignMissionNotDraft
+		}
+
+		if err := s.claimSpyCat(ctx, tx, opts.SpyCatID, mission.ID); err != nil {
+			return err
+		}
+
+		mission.Status = entity.MissionStatusAssigned
+		if err := linkSpyCat(ctx, tx, mission, opts.SpyCatID, opts.Reason); err != nil {
+			logger.Error("Failed to link spy cat to mission", "err", err)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Spy cat assigned to mission successfully")
+	return nil
+}
+
+type UnassignSpyCatOptions struct {
+	Reason string
+}
+
+// UnassignSpyCat takes the spy cat off the mission and moves the mission back to draft.
+func (s *missionService) UnassignSpyCat(ctx context.Context, missionID string, opts UnassignSpyCatOptions) error {
+	logger := s.loggerFor(ctx)
+	logger.Info("Unassigning spy cat from mission", "missionID", missionID, "opts", opts)
+
+	err := s.storages.WithTx(ctx, func(tx Storages) error {
+		mission, err := tx.Mission.GetMissionForUpdate(ctx, missionID)
+		if err != nil {
+			logger.Error("Failed to get mission", "err", err)
+			return err
+		}
+		if mission == nil {
+			return ErrUnassignMissionNotFound
+		}
+
+		if mission.SpyCatID == nil {
+			return ErrUnassignMissionHasNoCat
+		}
+
+		if !mission.Status.CanTransitionTo(entity.MissionStatusDraft) {
+			return ErrUnassignMissionFinished
+		}
+
+		if err := unassignMission(ctx, tx, mission, opts.Reason); err != nil {
+			logger.Error("Failed to unassign mission", "err", err)
+			return err
+		}
+		return nil
+	})
 	if err != nil {
-		s.logger.Error("Failed to get mission", "err", err)
 		return err
 	}
+
+	logger.Info("Spy cat unassigned from mission successfully")
+	return nil
+}
+
+type ReassignSpyCatOptions struct {
+	SpyCatID string
+	Reason   string
+}
+
+// ReassignSpyCat hands the mission over to another spy cat keeping the mission status.
+func (s *missionService) ReassignSpyCat(ctx context.Context, missionID string, opts ReassignSpyCatOptions) error {
+	logger := s.loggerFor(ctx)
+	logger.Info("Reassigning mission to another spy cat", "missionID", missionID, "opts", opts)
+
+	err := s.storages.WithTx(ctx, func(tx Storages) error {
+		mission, err := tx.Mission.GetMissionForUpdate(ctx, missionID)
+		if err != nil {
+			logger.Error("Failed to get mission", "err", err)
+			return err
+		}
+		if mission == nil {
+			return ErrReassignMissionNotFound
+		}
+
+		if mission.SpyCatID == nil {
+			return ErrReassignMissionHasNoCat
+		}
+
+		if mission.Status.IsFinal() {
+			return ErrReassignMissionFinished
+		}
+
+		if *mission.SpyCatID == opts.SpyCatID {
+			return ErrReassignSameSpyCat
+		}
+
+		if err := s.claimSpyCat(ctx, tx, opts.SpyCatID, mission.ID); err != nil {
+			return err
+		}
+
+		if err := releaseSpyCat(ctx, tx, mission, opts.Reason); err != nil {
+			logger.Error("Failed to release spy cat", "err", err)
+			return err
+		}
+
+		if err := linkSpyCat(ctx, tx, mission, opts.SpyCatID, opts.Reason); err != nil {
+			logger.Error("Failed to link spy cat to mission", "err", err)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Mission reassigned successfully")
+	return nil
+}
+
+func (s *missionService) ListMissionAssignments(ctx context.Context, missionID string) ([]entity.Assignment, error) {
+	logger := s.loggerFor(ctx)
+	logger.Info("Listing mission assignments", "missionID", missionID)
+
+	mission, err := s.storage.GetMission(ctx, missionID)
+	if err != nil {
+		logger.Error("Failed to get mission", "err", err)
+		return nil, err
+	}
 	if mission == nil {
-		return errs.New("mission not found")
+		return nil, ErrListMissionAssignmentsNotFound
 	}
 
-	if mission.SpyCatID != nil {
-		return errs.New("mission already has an assigned cat")
+	assignments, err := s.storages.Assignment.ListMissionAssignments(ctx, missionID)
+	if err != nil {
+		logger.Error("Failed to list mission assignments", "err", err)
+		return nil, err
 	}
 
-	// Get spy cat
-	cat, err := s.storages.SpyCat.GetSpyCat(ctx, spyCatID)
+	logger.Info("Mission assignments listed successfully", "count", len(assignments))
+	return assignments, nil
+}
+
+// claimSpyCat marks the spy cat as busy with the mission if it exists and is not busy with another one.
+// The check and the update are a single conditional update, so concurrent assignments of the cat cannot both succeed.
+func (s *missionService) claimSpyCat(ctx context.Context, tx Storages, spyCatID, missionID string) error {
+	logger := s.loggerFor(ctx)
+	cat, err := tx.SpyCat.GetSpyCat(ctx, spyCatID)
 	if err != nil {
-		s.logger.Error("Failed to get spy cat", "err", err)
+		logger.Error("Failed to get spy cat", "err", err)
 		return err
 	}
 	if cat == nil {
-		return errs.New("spy cat not found")
+		return ErrAssignSpyCatNotFound
 	}
 
-	if cat.MissionID != nil {
-		return errs.New("spy cat is already assigned to a mission")
+	claimed, err := tx.SpyCat.AssignSpyCatMission(ctx, cat.ID, missionID)
+	if err != nil {
+		logger.Error("Failed to assign spy cat mission", "err", err)
+		return err
+	}
+	if !claimed {
+		return ErrAssignSpyCatBusy
 	}
+	return nil
+}
+
+// The spy cat and the mission reference each other while the mission is active:
+// SpyCat.MissionID is set if and only if Mission.SpyCatID is set and the mission is not final.
+// Missions in a final status keep SpyCatID to know who finished them.
+// The helpers below keep both references and the assignment history in sync,
+// so they must be called within a transaction on a mission locked by GetMissionForUpdate.
+// Only the changed columns are written, so concurrent updates of other fields are kept.
 
-	// Update mission with spy cat
+// linkSpyCat assigns the spy cat claimed by claimSpyCat to the mission and starts a new assignment.
+func linkSpyCat(ctx context.Context, tx Storages, mission *entity.Mission, spyCatID, reason string) error {
+	// reset preloaded spy cat, it is not the assigned one anymore
 	mission.SpyCatID = &spyCatID
-	if _, err := s.storage.UpdateMission(ctx, mission); err != nil {
-		s.logger.Error("Failed to update mission", "err", err)
+	mission.SpyCat = nil
+	if err := tx.Mission.UpdateMissionState(ctx, mission); err != nil {
 		return err
 	}
 
-	s.logger.Info("Spy cat assigned to mission successfully")
-	return nil
+	_, err := tx.Assignment.CreateAssignment(ctx, &entity.Assignment{
+		SpyCatID:  spyCatID,
+		MissionID: mission.ID,
+		StartedAt: time.Now(),
+		Reason:    reason,
+	})
+	return err
+}
+
+// releaseSpyCat ends the active assignment of the mission and frees its spy cat.
+// The mission itself is not updated.
+func releaseSpyCat(ctx context.Context, tx Storages, mission *entity.Mission, reason string) error {
+	if mission.SpyCatID == nil {
+		return nil
+	}
+
+	assignment, err := tx.Assignment.GetActiveAssignment(ctx, mission.ID)
+	if err != nil {
+		return err
+	}
+	if assignment == nil {
+		return fmt.Errorf("mission %s has a spy cat but no active assignment", mission.ID)
+	}
+
+	now := time.Now()
+	assignment.EndedAt = &now
+	assignment.EndReason = reason
+	if _, err := tx.Assignment.UpdateAssignment(ctx, assignment); err != nil {
+		return err
+	}
+
+	return tx.SpyCat.ReleaseSpyCatMission(ctx, *mission.SpyCatID, mission.ID)
+}
+
+// unassignMission frees the spy cat of the mission and moves the mission back to draft.
+func unassignMission(ctx context.Context, tx Storages, mission *entity.Mission, reason string) error {
+	if err := releaseSpyCat(ctx, tx, mission, reason); err != nil {
+		return err
+	}
+
+	mission.SpyCatID = nil
+	mission.SpyCat = nil
+	mission.Status = entity.MissionStatusDraft
+	return tx.Mission.UpdateMissionState(ctx, mission)
+}
+
+// finishMission moves the mission to passed status and frees its spy cat if the status is final.
+func finishMission(ctx context.Context, tx Storages, mission *entity.Mission, status entity.MissionStatus) error {
+	if status.IsFinal() {
+		if err := releaseSpyCat(ctx, tx, mission, "mission "+string(status)); err != nil {
+			return err
+		}
+	}
+
+	if status == entity.MissionStatusCompleted {
+		now := time.Now()
+		mission.CompletedAt = &now
+	}
+	mission.Status = status
+	return tx.Mission.UpdateMissionState(ctx, mission)
 }
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func TestDecodeCursor(t *testing.T) {
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	t.Run("round trip", func(t *testing.T) {
+		want := Cursor{Sort: "name", Value: "Tom", ID: "0b8c2c7e"}
+		got, err := DecodeCursor(want.Encode())
+		if err != nil {
+			t.Fatalf("DecodeCursor() error = %v", err)
+		}
+		if got == nil || *got != want {
+			t.Fatalf("DecodeCursor() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("empty string", func(t *testing.T) {
+		got, err := DecodeCursor("")
+		if err != nil || got != nil {
+			t.Fatalf("DecodeCursor(\"\") = %+v, %v, want nil, nil", got, err)
+		}
+	})
+
+	invalid := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "not base64", cursor: "not a cursor!"},
+		{name: "padded base64", cursor: base64.URLEncoding.EncodeToString([]byte(`{"id":"1"}`))},
+		{name: "not json", cursor: encode("id=1")},
+		{name: "wrong json type", cursor: encode(`["1"]`)},
+		{name: "missing id", cursor: encode(`{"s":"name","v":"Tom"}`)},
+		{name: "empty id", cursor: encode(`{"id":""}`)},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeCursor(tt.cursor)
+			if !errors.Is(err, ErrInvalidCursor) {
+				t.Fatalf("DecodeCursor(%q) error = %v, want %v", tt.cursor, err, ErrInvalidCursor)
+			}
+			if got != nil {
+				t.Fatalf("DecodeCursor(%q) = %+v, want nil", tt.cursor, got)
+			}
+		})
+	}
+}
+
+func TestPageLimit(t *testing.T) {
+	tests := []struct {
+		limit int
+		want  int
+	}{
+		{limit: -1, want: defaultPageLimit},
+		{limit: 0, want: defaultPageLimit},
+		{limit: 1, want: 1},
+		{limit: maxPageLimit, want: maxPageLimit},
+		{limit: maxPageLimit + 1, want: maxPageLimit},
+	}
+	for _, tt := range tests {
+		if got := pageLimit(tt.limit); got != tt.want {
+			t.Errorf("pageLimit(%d) = %d, want %d", tt.limit, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/Kontentski/develops-today-task/internal/entity"
+	"github.com/Kontentski/develops-today-task/pkg/postgresql"
+)
+
+type payrollService struct {
+	serviceContext
+}
+
+func NewPayrollService(options Options) PayrollService {
+	return &payrollService{
+		serviceContext: serviceContext{
+			storages: options.Storages,
+			cfg:      options.Config,
+			apis:     options.APIs,
+			logger:   options.Logger.Named("PayrollService"),
+		},
+	}
+}
+
+// PayrollLine is the pay of a single spy cat for a month.
+type PayrollLine struct {
+	SpyCatID          string    `json:"spyCatId"`
+	Name              string    `json:"name"`
+	Breed             string    `json:"breed"`
+	EmployedFrom      time.Time `json:"employedFrom"`
+	EmployedTo        time.Time `json:"employedTo"`
+	BasePay           float64   `json:"basePay"`
+	CompletedMissions int       `json:"completedMissions"`
+	Bonus             float64   `json:"bonus"`
+	Total             float64   `json:"total"`
+}
+
+// PayrollReport is the pay of all spy cats employed in a month.
+type PayrollReport struct {
+	Month string        `json:"month"`
+	Lines []PayrollLine `json:"lines"`
+	Total float64       `json:"total"`
+}
+
+// PayrollMonthFormat is the layout of payroll month, e.g. 2026-09.
+const PayrollMonthFormat = "2006-01"
+
+// MonthlyReport calculates the pay of every spy cat for the month containing passed time.
+// Monthly salary is prorated by the time the cat was employed and by salary changes within the month.
+func (s *payrollService) MonthlyReport(ctx context.Context, month time.Time) (*PayrollReport, error) {
+	logger := s.loggerFor(ctx)
+	from := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	logger.Info("Building payroll report", "from", from, "to", to)
+
+	// report queries scan the whole month and may take longer than regular ones
+	ctx = postgresql.WithStatementTimeout(ctx, s.cfg.Payroll.QueryTimeout)
+
+	cats, err := s.storages.SpyCat.ListEmployedSpyCats(ctx, from, to)
+	if err != nil {
+		logger.Error("Failed to list employed spy cats", "err", err)
+		return nil, err
+	}
+
+	ids := make([]string, len(cats))
+	for i, cat := range cats {
+		ids[i] = cat.ID
+	}
+
+	changes, err := s.storages.SalaryChange.ListSpyCatsSalaryChanges(ctx, ids, to)
+	if err != nil {
+		logger.Error("Failed to list salary changes", "err", err)
+		return nil, err
+	}
+	changesByCat := make(map[string][]entity.SalaryChange, len(cats))
+	for _, change := range changes {
+		changesByCat[change.SpyCatID] = append(changesByCat[change.SpyCatID], change)
+	}
+
+	completedMissions, err := s.storages.Mission.CountCompletedMissions(ctx, from, to)
+	if err != nil {
+		logger.Error("Failed to count completed missions", "err", err)
+		return nil, err
+	}
+
+	report := &PayrollReport{
+		Month: from.Format(PayrollMonthFormat),
+		Lines: make([]PayrollLine, 0, len(cats)),
+	}
+	for i := range cats {
+		cat := &cats[i]
+
+		employedFrom, employedTo := from, to
+		if cat.CreatedAt.After(employedFrom) {
+			employedFrom = cat.CreatedAt
+		}
+		if cat.DeletedAt.Valid && cat.DeletedAt.Time.Before(employedTo) {
+			employedTo = cat.DeletedAt.Time
+		}
+
+		line := PayrollLine{
+			SpyCatID:          cat.ID,
+			Name:              cat.Name,
+			Breed:             cat.Breed,
+			EmployedFrom:      employedFrom,
+			EmployedTo:        employedTo,
+			BasePay:           roundCents(proratedPay(cat, changesByCat[cat.ID], employedFrom, employedTo, to.Sub(from))),
+			CompletedMissions: completedMissions[cat.ID],
+		}
+		line.Bonus = roundCents(float64(line.CompletedMissions) * s.cfg.Payroll.MissionBonus)
+		line.Total = roundCents(line.BasePay + line.Bonus)
+
+		report.Lines = append(report.Lines, line)
+		report.Total += line.Total
+	}
+	report.Total = roundCents(report.Total)
+
+	logger.Info("Payroll report built successfully", "month", report.Month, "count", len(report.Lines))
+	return report, nil
+}
+
+// proratedPay returns the part of the monthly salary earned in [from, to).
+// Changes must be ordered by effective date.
+func proratedPay(cat *entity.SpyCat, changes []entity.SalaryChange, from, to time.Time, month time.Duration) float64 {
+	if !to.After(from) {
+		return 0
+	}
+
+	// salary before the first recorded change, cats created before
+	// the salary history was introduced have no changes at all
+	salary := cat.Salary
+	if len(changes) > 0 {
+		salary = changes[0].OldSalary
+	}
+
+	i := 0
+	for ; i < len(changes) && !changes[i].EffectiveAt.After(from); i++ {
+		salary = changes[i].NewSalary
+	}
+
+	pay := 0.0
+	start := from
+	for ; i < len(changes) && changes[i].EffectiveAt.Before(to); i++ {
+		pay += salary * float64(changes[i].EffectiveAt.Sub(start)) / float64(month)
+		start = changes[i].EffectiveAt
+		salary = changes[i].NewSalary
+	}
+	pay += salary * float64(to.Sub(start)) / float64(month)
+
+	return pay
+}
+
+// roundCents rounds passed amount to cents.
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Kontentski/develops-today-task/internal/entity"
+)
+
+func TestProratedPay(t *testing.T) {
+	from := time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	month := to.Sub(from)
+	day := func(d int) time.Time { return from.AddDate(0, 0, d-1) }
+	change := func(effectiveAt time.Time, oldSalary, newSalary float64) entity.SalaryChange {
+		return entity.SalaryChange{EffectiveAt: effectiveAt, OldSalary: oldSalary, NewSalary: newSalary}
+	}
+
+	tests := []struct {
+		name    string
+		salary  float64
+		changes []entity.SalaryChange
+		from    time.Time
+		to      time.Time
+		want    float64
+	}{
+		{
+			name:   "no history pays current salary",
+			salary: 3000,
+			from:   from,
+			to:     to,
+			want:   3000,
+		},
+		{
+			name:   "empty period",
+			salary: 3000,
+			from:   to,
+			to:     from,
+			want:   0,
+		},
+		{
+			name:   "employed for a part of the month",
+			salary: 3000,
+			from:   day(16),
+			to:     to,
+			want:   1500,
+		},
+		{
+			name:    "change before the month applies to the whole month",
+			salary:  4000,
+			changes: []entity.SalaryChange{change(from.AddDate(0, -1, 0), 3000, 4000)},
+			from:    from,
+			to:      to,
+			want:    4000,
+		},
+		{
+			name:    "change at the start of the month applies to the whole month",
+			salary:  4000,
+			changes: []entity.SalaryChange{change(from, 3000, 4000)},
+			from:    from,
+			to:      to,
+			want:    4000,
+		},
+		{
+			name:    "change in the middle of the month splits it",
+			salary:  6000,
+			changes: []entity.SalaryChange{change(day(16), 3000, 6000)},
+			from:    from,
+			to:      to,
+			want:    4500,
+		},
+		{
+			// the current salary is already the new one, the old one is taken from the change
+			name:    "change after the month uses the old salary",
+			salary:  6000,
+			changes: []entity.SalaryChange{change(to, 3000, 6000)},
+			from:    from,
+			to:      to,
+			want:    3000,
+		},
+		{
+			name:   "several changes within the month",
+			salary: 9000,
+			changes: []entity.SalaryChange{
+				change(day(11), 3000, 6000),
+				change(day(21), 6000, 9000),
+			},
+			from: from,
+			to:   to,
+			want: 6000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cat := &entity.SpyCat{Salary: tt.salary}
+			got := proratedPay(cat, tt.changes, tt.from, tt.to, month)
+			if math.Abs(got-tt.want) > 0.005 {
+				t.Errorf("proratedPay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundCents(t *testing.T) {
+	tests := map[float64]float64{
+		0:        0,
+		1.004:    1,
+		1.005001: 1.01,
+		99.999:   100,
+		-1.234:   -1.23,
+	}
+	for amount, want := range tests {
+		if got := roundCents(amount); got != want {
+			t.Errorf("roundCents(%v) = %v, want %v", amount, got, want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Kontentski/develops-today-task/internal/entity"
+	"github.com/Kontentski/develops-today-task/pkg/logging"
+)
+
+// principalKey is the context key of the authenticated principal.
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the principal.
+func WithPrincipal(ctx context.Context, principal *entity.Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal of ctx, nil for anonymous calls and background jobs.
+func PrincipalFromContext(ctx context.Context) *entity.Principal {
+	principal, _ := ctx.Value(principalKey{}).(*entity.Principal)
+	return principal
+}
+
+// principalID returns the ID of the principal of ctx, empty for anonymous calls and background jobs.
+func principalID(ctx context.Context) string {
+	if principal := PrincipalFromContext(ctx); principal != nil {
+		return principal.ID
+	}
+	return ""
+}
+
+// loggerFor returns the service logger recording the principal of ctx.
+func (c *serviceContext) loggerFor(ctx context.Context) logging.Logger {
+	principal := PrincipalFromContext(ctx)
+	if principal == nil {
+		return c.logger
+	}
+	return c.logger.With("principalId", principal.ID, "principalRole", principal.Role)
+}
+
+// canAccessMission reports whether the principal of ctx may access the mission.
+// Agents may access only missions assigned to their spy cats, other principals access all missions.
+func canAccessMission(ctx context.Context, mission *entity.Mission) bool {
+	principal := PrincipalFromContext(ctx)
+	if principal == nil || principal.Role != entity.RoleAgent {
+		return true
+	}
+	return mission != nil && mission.SpyCatID != nil && *mission.SpyCatID == principal.ID
+}
@@ -2,18 +2,24 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/Kontentski/develops-today-task/config"
 	"github.com/Kontentski/develops-today-task/pkg/errs"
 	"github.com/Kontentski/develops-today-task/pkg/logging"
 
+	"github.com/Kontentski/develops-today-task/internal/api/cat"
 	"github.com/Kontentski/develops-today-task/internal/entity"
 )
 
 type Services struct {
-	SpyCat  SpyCatService
-	Mission MissionService
-	Target  TargetService
+	SpyCat      SpyCatService
+	Mission     MissionService
+	Target      TargetService
+	Payroll     PayrollService
+	Breed       BreedService
+	APIKey      APIKeyService
+	Idempotency IdempotencyService
 }
 
 // serviceContext provides a shared context for all services
@@ -32,56 +38,118 @@ type Options struct {
 	Logger   logging.Logger
 }
 
+// Pagination errors
+var (
+	ErrInvalidCursor = errs.Validation("invalid_cursor", "invalid cursor")
+)
+
 // SpyCat errors
 var (
-	ErrCreateSpyCatInvalidBreed = errs.New("invalid breed")
-	ErrDeleteSpyCatNotFound     = errs.New("spy cat not found")
-	ErrUpdateSpyCatNotFound     = errs.New("spy cat not found")
-	ErrGetSpyCatNotFound        = errs.New("spy cat not found")
+	ErrCreateSpyCatInvalidBreed        = errs.Validation("invalid_breed", "invalid breed")
+	ErrDeleteSpyCatNotFound            = errs.NotFound("spy_cat_not_found", "spy cat not found")
+	ErrUpdateSpyCatNotFound            = errs.NotFound("spy_cat_not_found", "spy cat not found")
+	ErrGetSpyCatNotFound               = errs.NotFound("spy_cat_not_found", "spy cat not found")
+	ErrListSpyCatsInvalidExperience    = errs.Validation("invalid_experience_range", "minExperience must not be greater than maxExperience")
+	ErrListSpyCatsInvalidSalary        = errs.Validation("invalid_salary_range", "minSalary must not be greater than maxSalary")
+	ErrScheduleSalaryChangeNotFound    = errs.NotFound("spy_cat_not_found", "spy cat not found")
+	ErrScheduleSalaryChangeNotInFuture = errs.Validation("effective_date_not_in_future", "effective date must be in the future")
+	ErrListSalaryHistoryNotFound       = errs.NotFound("spy_cat_not_found", "spy cat not found")
+	ErrListSpyCatAssignmentsNotFound   = errs.NotFound("spy_cat_not_found", "spy cat not found")
+)
+
+// Breed errors
+var (
+	ErrCreateBreedInvalidID  = errs.Validation("invalid_breed_id", "breed id must consist of lowercase letters, digits and dashes")
+	ErrCreateBreedExists     = errs.Conflict("breed_exists", "breed with this id or name already exists")
+	ErrGetBreedSyncNotFound  = errs.NotFound("breed_sync_not_found", "breeds have not been synced yet")
+	ErrGetBreedStatsNotFound = errs.NotFound("breed_not_found", "breed not found")
+	ErrGetCatAPIQuotaUnknown = errs.NotFound("cat_api_quota_unknown", "thecatapi quota is not known yet")
+)
+
+// APIKey errors
+var (
+	ErrCreateAPIKeyInvalidScope      = errs.Validation("invalid_scope", "unknown scope")
+	ErrCreateAPIKeyExpiryNotInFuture = errs.Validation("expiry_not_in_future", "expiry must be in the future")
+	ErrRevokeAPIKeyNotFound          = errs.NotFound("api_key_not_found", "api key not found")
+	ErrRevokeAPIKeyRevoked           = errs.Conflict("api_key_revoked", "api key is already revoked")
+	ErrAuthenticateAPIKeyInvalid     = errs.Unauthenticated("invalid_api_key", "api key is invalid, expired or revoked")
+)
+
+// Idempotency errors
+var (
+	ErrBeginIdempotentRequestInvalidKey = errs.Validation("invalid_idempotency_key", "idempotency key must have between 1 and 255 characters")
+	ErrBeginIdempotentRequestMismatch   = errs.Conflict("idempotency_key_reused", "idempotency key was already used with a different request")
+	ErrBeginIdempotentRequestInProgress = errs.Conflict("idempotency_key_in_progress", "request with this idempotency key is still in progress")
 )
 
 // Mission errors
 var (
-	ErrCreateMissionInvalidTargets = errs.New("mission must have between 1 and 3 targets")
-	ErrGetMissionNotFound          = errs.New("mission not found")
-	ErrDeleteMissionNotFound       = errs.New("mission not found")
-	ErrDeleteMissionAssigned       = errs.New("cannot delete mission assigned to a cat")
-	ErrUpdateMissionNotFound       = errs.New("mission not found")
-	ErrAssignMissionNotFound       = errs.New("mission not found")
-	ErrAssignMissionHasCat         = errs.New("mission already has an assigned cat")
-	ErrAssignSpyCatNotFound        = errs.New("spy cat not found")
-	ErrAssignSpyCatBusy            = errs.New("spy cat is already assigned to a mission")
+	ErrCreateMissionInvalidTargets        = errs.Validation("invalid_target_count", "mission must have between 1 and 3 targets")
+	ErrGetMissionNotFound                 = errs.NotFound("mission_not_found", "mission not found")
+	ErrGetMissionForbidden                = errs.Forbidden("mission_not_assigned_to_agent", "mission is not assigned to you")
+	ErrDeleteMissionNotFound              = errs.NotFound("mission_not_found", "mission not found")
+	ErrDeleteMissionAssigned              = errs.Conflict("mission_assigned", "cannot delete mission assigned to a cat")
+	ErrTransitionMissionNotFound          = errs.NotFound("mission_not_found", "mission not found")
+	ErrTransitionMissionUnknownStatus     = errs.Validation("unknown_mission_status", "unknown mission status")
+	ErrTransitionMissionAssignment        = errs.Validation("transition_requires_assignment", "missions become assigned or draft only by assigning or unassigning a spy cat")
+	ErrTransitionMissionTargetsIncomplete = errs.Conflict("mission_targets_incomplete", "cannot complete mission with incomplete targets")
+	ErrTransitionMissionNotAllowed        = errs.Conflict("mission_transition_not_allowed", "cannot transition mission")
+	ErrAssignMissionNotFound              = errs.NotFound("mission_not_found", "mission not found")
+	ErrAssignMissionHasCat                = errs.Conflict("mission_has_spy_cat", "mission already has an assigned cat")
+	ErrAssignMissionNotDraft              = errs.Conflict("mission_not_draft", "spy cat can be assigned only to a draft mission")
+	ErrAssignSpyCatNotFound               = errs.NotFound("spy_cat_not_found", "spy cat not found")
+	ErrAssignSpyCatBusy                   = errs.Conflict("spy_cat_busy", "spy cat is already assigned to a mission")
+	ErrUnassignMissionNotFound            = errs.NotFound("mission_not_found", "mission not found")
+	ErrUnassignMissionHasNoCat            = errs.Conflict("mission_has_no_spy_cat", "mission has no assigned cat")
+	ErrUnassignMissionFinished            = errs.Conflict("mission_finished", "cannot unassign spy cat from completed or aborted mission")
+	ErrReassignMissionNotFound            = errs.NotFound("mission_not_found", "mission not found")
+	ErrReassignMissionHasNoCat            = errs.Conflict("mission_has_no_spy_cat", "mission has no assigned cat, assign one instead")
+	ErrReassignMissionFinished            = errs.Conflict("mission_finished", "cannot reassign completed or aborted mission")
+	ErrReassignSameSpyCat                 = errs.Conflict("spy_cat_already_assigned", "spy cat is already assigned to this mission")
+	ErrListMissionAssignmentsNotFound     = errs.NotFound("mission_not_found", "mission not found")
+	ErrListMissionsAssignmentConflict     = errs.Validation("conflicting_filters", "spyCatId and unassigned filters cannot be combined")
+	ErrListMissionsInvalidCreatedRange    = errs.Validation("invalid_created_range", "createdFrom must not be after createdTo")
+	ErrListMissionsInvalidStatus          = errs.Validation("invalid_status_filter", "status must be one of draft, assigned, in_progress, completed, aborted")
 )
 
 // Target errors
 var (
-	ErrCreateTargetMissionNotFound  = errs.New("mission not found")
-	ErrCreateTargetCompletedMission = errs.New("cannot add target to completed mission")
-	ErrCreateTargetTooMany          = errs.New("mission cannot have more than 3 targets")
-	ErrGetTargetNotFound            = errs.New("target not found")
-	ErrUpdateTargetNotFound         = errs.New("target not found")
-	ErrUpdateTargetCompletedMission = errs.New("cannot update target in completed mission")
-	ErrDeleteTargetNotFound         = errs.New("target not found")
-	ErrDeleteTargetCompleted        = errs.New("cannot delete completed target")
+	ErrCreateTargetMissionNotFound  = errs.NotFound("mission_not_found", "mission not found")
+	ErrCreateTargetCompletedMission = errs.Conflict("mission_finished", "cannot add target to completed or aborted mission")
+	ErrCreateTargetTooMany          = errs.Conflict("too_many_targets", "mission cannot have more than 3 targets")
+	ErrGetTargetNotFound            = errs.NotFound("target_not_found", "target not found")
+	ErrUpdateTargetNotFound         = errs.NotFound("target_not_found", "target not found")
+	ErrUpdateTargetForbidden        = errs.Forbidden("mission_not_assigned_to_agent", "target belongs to a mission not assigned to you")
+	ErrUpdateTargetCompletedMission = errs.Conflict("mission_finished", "cannot update target in completed or aborted mission")
+	ErrDeleteTargetNotFound         = errs.NotFound("target_not_found", "target not found")
+	ErrDeleteTargetCompleted        = errs.Conflict("target_completed", "cannot delete completed target")
 )
 
 // SpyCatService defines service operations for SpyCat.
 type SpyCatService interface {
 	CreateSpyCat(ctx context.Context, opts CreateSpyCatOptions) (*entity.SpyCat, error)
 	GetSpyCat(ctx context.Context, id string) (*entity.SpyCat, error)
-	UpdateSpyCatSalary(ctx context.Context, id string, newSalary float64) (*entity.SpyCat, error)
+	UpdateSpyCatSalary(ctx context.Context, id string, opts UpdateSpyCatSalaryOptions) (*entity.SpyCat, error)
+	ScheduleSalaryChange(ctx context.Context, id string, opts ScheduleSalaryChangeOptions) (*entity.SalaryChange, error)
+	ListSalaryHistory(ctx context.Context, id string) ([]entity.SalaryChange, error)
+	ApplyScheduledSalaryChanges(ctx context.Context) (int, error)
+	ListSpyCatAssignments(ctx context.Context, id string) ([]entity.Assignment, error)
 	DeleteSpyCat(ctx context.Context, id string) error
-	ListSpyCats(ctx context.Context) ([]entity.SpyCat, error)
+	ListSpyCats(ctx context.Context, opts ListSpyCatsOptions) (*SpyCatPage, error)
 }
 
 // MissionService defines service operations for Mission.
 type MissionService interface {
 	CreateMission(ctx context.Context, opts CreateMissionOptions) (*entity.Mission, error)
 	GetMission(ctx context.Context, id string) (*entity.Mission, error)
-	UpdateMission(ctx context.Context, id string, opts UpdateMissionOptions) (*entity.Mission, error)
+	TransitionMission(ctx context.Context, id string, opts TransitionMissionOptions) (*entity.Mission, error)
 	DeleteMission(ctx context.Context, id string) error
-	ListMissions(ctx context.Context) ([]entity.Mission, error)
-	AssignSpyCat(ctx context.Context, missionID, spyCatID string) error
+	ListMissions(ctx context.Context, opts ListMissionsOptions) (*MissionPage, error)
+	ListMissionSummaries(ctx context.Context, opts ListMissionsOptions) (*MissionSummaryPage, error)
+	AssignSpyCat(ctx context.Context, missionID string, opts AssignSpyCatOptions) error
+	UnassignSpyCat(ctx context.Context, missionID string, opts UnassignSpyCatOptions) error
+	ReassignSpyCat(ctx context.Context, missionID string, opts ReassignSpyCatOptions) error
+	ListMissionAssignments(ctx context.Context, missionID string) ([]entity.Assignment, error)
 }
 
 // TargetService defines service operations for Target.
@@ -92,10 +160,48 @@ type TargetService interface {
 	ListTargets(ctx context.Context, missionID string) ([]entity.Target, error)
 }
 
+// PayrollService defines service operations for payroll.
+type PayrollService interface {
+	MonthlyReport(ctx context.Context, month time.Time) (*PayrollReport, error)
+}
+
+// BreedService defines service operations for Breed.
+type BreedService interface {
+	SyncBreeds(ctx context.Context) (*entity.BreedSync, error)
+	GetLastBreedSync(ctx context.Context) (*entity.BreedSync, error)
+	CreateBreed(ctx context.Context, opts CreateBreedOptions) (*entity.Breed, error)
+	ListBreeds(ctx context.Context, opts ListBreedsOptions) ([]entity.Breed, error)
+	GetBreedStats(ctx context.Context, id string) (*entity.BreedStats, error)
+	GetCatAPIQuota(ctx context.Context) (*cat.Quota, error)
+	RefreshBreedProfiles(ctx context.Context) (*BreedProfilesRefresh, error)
+}
+
+// APIKeyService defines service operations for APIKey.
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, opts CreateAPIKeyOptions) (*CreatedAPIKey, error)
+	ListAPIKeys(ctx context.Context) ([]entity.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string) (*entity.APIKey, error)
+	AuthenticateAPIKey(ctx context.Context, key string) (*entity.Principal, error)
+}
+
+// IdempotencyService defines service operations for IdempotencyKey.
+type IdempotencyService interface {
+	// BeginIdempotentRequest claims the key of the request, a completed key is returned for replay.
+	BeginIdempotentRequest(ctx context.Context, opts BeginIdempotentRequestOptions) (*entity.IdempotencyKey, error)
+	CompleteIdempotentRequest(ctx context.Context, key *entity.IdempotencyKey, opts CompleteIdempotentRequestOptions) error
+	// ReleaseIdempotentRequest forgets the key of a failed request, so it can be retried.
+	ReleaseIdempotentRequest(ctx context.Context, key *entity.IdempotencyKey) error
+	PurgeExpiredIdempotencyKeys(ctx context.Context) (int, error)
+}
+
 func NewService(options Options) Services {
 	return Services{
-		SpyCat:  NewSpyCatService(options, options.Storages.SpyCat),
-		Mission: NewMissionService(options, options.Storages.Mission),
-		Target:  NewTargetService(options, options.Storages.Target),
+		SpyCat:      NewSpyCatService(options, options.Storages.SpyCat),
+		Mission:     NewMissionService(options, options.Storages.Mission),
+		Target:      NewTargetService(options, options.Storages.Target),
+		Payroll:     NewPayrollService(options),
+		Breed:       NewBreedService(options),
+		APIKey:      NewAPIKeyService(options),
+		Idempotency: NewIdempotencyService(options),
 	}
 }
@@ -2,9 +2,12 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Kontentski/develops-today-task/internal/entity"
-	"gorm.io/gorm"
 )
 
 type spyCatService struct {
@@ -32,107 +35,459 @@ type CreateSpyCatOptions struct {
 }
 
 func (s *spyCatService) CreateSpyCat(ctx context.Context, opts CreateSpyCatOptions) (*entity.SpyCat, error) {
-	s.logger.Info("Creating new spy cat", "opts", opts)
+	logger := s.loggerFor(ctx)
+	logger.Info("Creating new spy cat", "opts", opts)
 
-	// Validate breed via TheCatAPI
-	if err := s.validateBreed(opts.Breed); err != nil {
-		s.logger.Error("Invalid breed", "err", err)
+	// Validate breed against the breed registry
+	breed, err := s.resolveBreed(ctx, opts.Breed)
+	if err != nil {
+		logger.Error("Invalid breed", "err", err)
 		return nil, err
 	}
 
 	cat := &entity.SpyCat{
 		Name:              opts.Name,
 		YearsOfExperience: opts.YearsOfExperience,
-		Breed:             opts.Breed,
+		Breed:             breed.Name,
+		BreedID:           &breed.ID,
+		BreedProfile:      breed.Profile(),
 		Salary:            opts.Salary,
 	}
 
-	createdCat, err := s.storages.SpyCat.CreateSpyCat(ctx, cat)
+	var createdCat *entity.SpyCat
+	err = s.storages.WithTx(ctx, func(tx Storages) error {
+		var err error
+		createdCat, err = tx.SpyCat.CreateSpyCat(ctx, cat)
+		if err != nil {
+			logger.Error("Failed to create spy cat", "err", err)
+			return err
+		}
+
+		// start salary history with the initial salary
+		_, err = tx.SalaryChange.CreateSalaryChange(ctx, &entity.SalaryChange{
+			SpyCatID:    createdCat.ID,
+			NewSalary:   createdCat.Salary,
+			EffectiveAt: createdCat.CreatedAt,
+			Reason:      initialSalaryReason,
+			Actor:       principalID(ctx),
+			AppliedAt:   &createdCat.CreatedAt,
+		})
+		if err != nil {
+			logger.Error("Failed to create initial salary change", "err", err)
+			return err
+		}
+		return nil
+	})
 	if err != nil {
-		s.logger.Error("Failed to create spy cat", "err", err)
 		return nil, err
 	}
 
-	s.logger.Info("Spy cat created successfully", "cat", createdCat)
+	logger.Info("Spy cat created successfully", "cat", createdCat)
 	return createdCat, nil
 }
 
-func (s *spyCatService) validateBreed(breed string) error {
-	breeds, err := s.apis.CatAPI.GetBreeds()
+// resolveBreed finds the stored breed by ID or name in any case.
+// The error of an unknown breed suggests the closest breeds.
+func (s *spyCatService) resolveBreed(ctx context.Context, breed string) (*entity.Breed, error) {
+	logger := s.loggerFor(ctx)
+	breeds, err := s.storages.Breed.ListBreeds(ctx, ListBreedsOptions{})
 	if err != nil {
-		return err
+		logger.Error("Failed to list breeds", "err", err)
+		return nil, err
 	}
 
-	for _, b := range breeds {
-		if b.Name == breed {
-			return nil
-		}
+	if b := findBreed(breeds, breed); b != nil {
+		return b, nil
 	}
-	return ErrCreateSpyCatInvalidBreed
+
+	if suggestions := suggestBreeds(breeds, breed); len(suggestions) > 0 {
+		return nil, fmt.Errorf("%w, did you mean: %s?", ErrCreateSpyCatInvalidBreed, strings.Join(suggestions, ", "))
+	}
+	return nil, ErrCreateSpyCatInvalidBreed
 }
 
 func (s *spyCatService) DeleteSpyCat(ctx context.Context, id string) error {
-	s.logger.Info("Deleting spy cat", "id", id)
+	logger := s.loggerFor(ctx)
+	logger.Info("Deleting spy cat", "id", id)
+
+	err := s.storages.WithTx(ctx, func(tx Storages) error {
+		cat, err := tx.SpyCat.GetSpyCat(ctx, id)
+		if err != nil {
+			logger.Error("Failed to get spy cat", "err", err)
+			return err
+		}
+		if cat == nil {
+			return ErrDeleteSpyCatNotFound
+		}
+
+		// take the cat off its active mission
+		if cat.MissionID != nil {
+			mission, err := tx.Mission.GetMissionForUpdate(ctx, *cat.MissionID)
+			if err != nil {
+				logger.Error("Failed to get mission", "err", err)
+				return err
+			}
+			if mission != nil && mission.Status.CanTransitionTo(entity.MissionStatusDraft) {
+				if err := unassignMission(ctx, tx, mission, "spy cat deleted"); err != nil {
+					logger.Error("Failed to unassign mission", "err", err)
+					return err
+				}
+			}
+		}
 
-	err := s.storages.SpyCat.DeleteSpyCat(ctx, id)
-	if err == gorm.ErrRecordNotFound {
-		s.logger.Error("Failed to delete spy cat", "err", err)
-		return ErrDeleteSpyCatNotFound
+		if err := tx.SpyCat.DeleteSpyCat(ctx, id); err != nil {
+			logger.Error("Failed to delete spy cat", "err", err)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	s.logger.Info("Spy cat deleted successfully", "id", id)
+	logger.Info("Spy cat deleted successfully", "id", id)
 	return nil
 }
 
-func (s *spyCatService) UpdateSpyCatSalary(ctx context.Context, id string, newSalary float64) (*entity.SpyCat, error) {
-	s.logger.Info("Updating spy cat salary", "id", id, "newSalary", newSalary)
+// initialSalaryReason is the reason of the salary change recorded on spy cat creation.
+const initialSalaryReason = "initial salary"
+
+type UpdateSpyCatSalaryOptions struct {
+	Salary float64
+	Reason string
+}
+
+func (s *spyCatService) UpdateSpyCatSalary(ctx context.Context, id string, opts UpdateSpyCatSalaryOptions) (*entity.SpyCat, error) {
+	logger := s.loggerFor(ctx)
+	logger.Info("Updating spy cat salary", "id", id, "opts", opts)
+
+	var updatedCat *entity.SpyCat
+	err := s.storages.WithTx(ctx, func(tx Storages) error {
+		// lock the cat, so the recorded old salary is not changed concurrently
+		cat, err := tx.SpyCat.GetSpyCatForUpdate(ctx, id)
+		if err != nil {
+			logger.Error("Failed to get spy cat", "err", err)
+			return err
+		}
+		if cat == nil {
+			logger.Info("Spy cat not found", "id", id)
+			return ErrUpdateSpyCatNotFound
+		}
+
+		now := time.Now()
+		change := &entity.SalaryChange{
+			SpyCatID:    cat.ID,
+			OldSalary:   cat.Salary,
+			NewSalary:   opts.Salary,
+			EffectiveAt: now,
+			Reason:      opts.Reason,
+			Actor:       principalID(ctx),
+			AppliedAt:   &now,
+		}
+
+		if err := tx.SpyCat.SetSpyCatSalary(ctx, cat.ID, opts.Salary); err != nil {
+			logger.Error("Failed to set spy cat salary", "err", err)
+			return err
+		}
+		updatedCat, err = tx.SpyCat.GetSpyCat(ctx, cat.ID)
+		if err != nil {
+			logger.Error("Failed to get spy cat", "err", err)
+			return err
+		}
+
+		if _, err := tx.SalaryChange.CreateSalaryChange(ctx, change); err != nil {
+			logger.Error("Failed to create salary change", "err", err)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Spy cat salary updated successfully", "cat", updatedCat)
+	return updatedCat, nil
+}
+
+type ScheduleSalaryChangeOptions struct {
+	Salary      float64
+	EffectiveAt time.Time
+	Reason      string
+}
+
+// ScheduleSalaryChange records a salary change that is applied on its effective date.
+func (s *spyCatService) ScheduleSalaryChange(ctx context.Context, id string, opts ScheduleSalaryChangeOptions) (*entity.SalaryChange, error) {
+	logger := s.loggerFor(ctx)
+	logger.Info("Scheduling spy cat salary change", "id", id, "opts", opts)
+
+	if !opts.EffectiveAt.After(time.Now()) {
+		return nil, ErrScheduleSalaryChangeNotInFuture
+	}
 
 	cat, err := s.storages.SpyCat.GetSpyCat(ctx, id)
 	if err != nil {
-		s.logger.Error("Failed to get spy cat", "err", err)
+		logger.Error("Failed to get spy cat", "err", err)
 		return nil, err
 	}
 	if cat == nil {
-		s.logger.Info("Spy cat not found", "id", id)
-		return nil, ErrUpdateSpyCatNotFound
+		return nil, ErrScheduleSalaryChangeNotFound
 	}
 
-	cat.Salary = newSalary
-	updatedCat, err := s.storages.SpyCat.UpdateSpyCat(ctx, cat)
+	change, err := s.storages.SalaryChange.CreateSalaryChange(ctx, &entity.SalaryChange{
+		SpyCatID:    cat.ID,
+		OldSalary:   cat.Salary,
+		NewSalary:   opts.Salary,
+		EffectiveAt: opts.EffectiveAt,
+		Reason:      opts.Reason,
+		Actor:       principalID(ctx),
+	})
 	if err != nil {
-		s.logger.Error("Failed to update spy cat", "err", err)
+		logger.Error("Failed to create salary change", "err", err)
 		return nil, err
 	}
 
-	s.logger.Info("Spy cat salary updated successfully", "cat", updatedCat)
-	return updatedCat, nil
+	logger.Info("Spy cat salary change scheduled successfully", "change", change)
+	return change, nil
 }
 
-func (s *spyCatService) ListSpyCats(ctx context.Context) ([]entity.SpyCat, error) {
-	s.logger.Info("Listing all spy cats")
+func (s *spyCatService) ListSalaryHistory(ctx context.Context, id string) ([]entity.SalaryChange, error) {
+	logger := s.loggerFor(ctx)
+	logger.Info("Listing spy cat salary history", "id", id)
 
-	cats, err := s.storages.SpyCat.ListSpyCats(ctx)
+	cat, err := s.storages.SpyCat.GetSpyCat(ctx, id)
 	if err != nil {
-		s.logger.Error("Failed to list spy cats", "err", err)
+		logger.Error("Failed to get spy cat", "err", err)
 		return nil, err
 	}
+	if cat == nil {
+		return nil, ErrListSalaryHistoryNotFound
+	}
 
-	s.logger.Info("Spy cats listed successfully", "count", len(cats))
-	return cats, nil
+	changes, err := s.storages.SalaryChange.ListSalaryChanges(ctx, id)
+	if err != nil {
+		logger.Error("Failed to list salary changes", "err", err)
+		return nil, err
+	}
+
+	logger.Info("Spy cat salary history listed successfully", "count", len(changes))
+	return changes, nil
+}
+
+// ApplyScheduledSalaryChanges applies all scheduled salary changes which effective date has come.
+// It returns the number of applied changes.
+func (s *spyCatService) ApplyScheduledSalaryChanges(ctx context.Context) (int, error) {
+	logger := s.loggerFor(ctx)
+	now := time.Now()
+	changes, err := s.storages.SalaryChange.ListPendingSalaryChanges(ctx, now)
+	if err != nil {
+		logger.Error("Failed to list pending salary changes", "err", err)
+		return 0, err
+	}
+
+	applied := 0
+	for i := range changes {
+		change := &changes[i]
+
+		claimed := false
+		err := s.storages.WithTx(ctx, func(tx Storages) error {
+			// the job runs on every replica, the change is applied by the one claiming it first
+			var err error
+			claimed, err = tx.SalaryChange.ClaimSalaryChange(ctx, change.ID, now)
+			if err != nil {
+				logger.Error("Failed to claim salary change", "err", err)
+				return err
+			}
+			if !claimed {
+				return nil
+			}
+			change.AppliedAt = &now
+
+			cat, err := tx.SpyCat.GetSpyCatForUpdate(ctx, change.SpyCatID)
+			if err != nil {
+				logger.Error("Failed to get spy cat", "err", err)
+				return err
+			}
+
+			// the cat may be deleted since the change was scheduled
+			if cat == nil {
+				return nil
+			}
+
+			change.OldSalary = cat.Salary
+			if err := tx.SpyCat.SetSpyCatSalary(ctx, cat.ID, change.NewSalary); err != nil {
+				logger.Error("Failed to set spy cat salary", "err", err)
+				return err
+			}
+			if _, err := tx.SalaryChange.UpdateSalaryChange(ctx, change); err != nil {
+				logger.Error("Failed to update salary change", "err", err)
+				return err
+			}
+			return nil
+		})
+		if err != nil {
+			return applied, err
+		}
+		if claimed {
+			applied++
+		}
+	}
+
+	if applied > 0 {
+		logger.Info("Scheduled salary changes applied successfully", "count", applied)
+	}
+	return applied, nil
+}
+
+// SpyCatSort is a field spy cats can be sorted by.
+type SpyCatSort string
+
+const (
+	SpyCatSortCreatedAt  SpyCatSort = "createdAt"
+	SpyCatSortName       SpyCatSort = "name"
+	SpyCatSortExperience SpyCatSort = "yearsOfExperience"
+	SpyCatSortSalary     SpyCatSort = "salary"
+)
+
+// ListSpyCatsOptions is used to filter, sort and paginate spy cats.
+type ListSpyCatsOptions struct {
+	Breed         string
+	MinExperience *int
+	MaxExperience *int
+	MinSalary     *float64
+	MaxSalary     *float64
+	// Available filters cats by whether they have no mission assigned.
+	Available *bool
+	Sort      SpyCatSort
+	Desc      bool
+	Cursor    *Cursor
+	Limit     int
+}
+
+// sortKey returns sort representation stored in cursors, e.g. "-salary".
+func (o ListSpyCatsOptions) sortKey() string {
+	if o.Desc {
+		return "-" + string(o.Sort)
+	}
+	return string(o.Sort)
+}
+
+// SpyCatPage is a single page of spy cats.
+type SpyCatPage struct {
+	Items      []entity.SpyCat `json:"items"`
+	NextCursor string          `json:"nextCursor,omitempty"`
+}
+
+// SpyCatSortValue returns the value of the sort field of the cat in the form stored in cursors.
+func SpyCatSortValue(cat *entity.SpyCat, sort SpyCatSort) string {
+	switch sort {
+	case SpyCatSortName:
+		return cat.Name
+	case SpyCatSortExperience:
+		return strconv.Itoa(cat.YearsOfExperience)
+	case SpyCatSortSalary:
+		return strconv.FormatFloat(cat.Salary, 'f', -1, 64)
+	default:
+		return cat.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+}
+
+// ParseSpyCatSortValue converts a sort value produced by SpyCatSortValue to the type of the sort field.
+func ParseSpyCatSortValue(sort SpyCatSort, value string) (interface{}, error) {
+	switch sort {
+	case SpyCatSortName:
+		return value, nil
+	case SpyCatSortExperience:
+		return strconv.Atoi(value)
+	case SpyCatSortSalary:
+		return strconv.ParseFloat(value, 64)
+	default:
+		return time.Parse(time.RFC3339Nano, value)
+	}
+}
+
+func (s *spyCatService) ListSpyCats(ctx context.Context, opts ListSpyCatsOptions) (*SpyCatPage, error) {
+	logger := s.loggerFor(ctx)
+	logger.Info("Listing spy cats", "opts", opts)
+
+	if opts.Sort == "" {
+		opts.Sort = SpyCatSortCreatedAt
+	}
+	if opts.MinExperience != nil && opts.MaxExperience != nil && *opts.MinExperience > *opts.MaxExperience {
+		return nil, ErrListSpyCatsInvalidExperience
+	}
+	if opts.MinSalary != nil && opts.MaxSalary != nil && *opts.MinSalary > *opts.MaxSalary {
+		return nil, ErrListSpyCatsInvalidSalary
+	}
+	if opts.Cursor != nil {
+		if opts.Cursor.Sort != opts.sortKey() {
+			return nil, ErrInvalidCursor
+		}
+		if _, err := ParseSpyCatSortValue(opts.Sort, opts.Cursor.Value); err != nil {
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	// fetch one extra row to know if there is a next page
+	limit := pageLimit(opts.Limit)
+	opts.Limit = limit + 1
+
+	cats, err := s.storages.SpyCat.ListSpyCats(ctx, opts)
+	if err != nil {
+		logger.Error("Failed to list spy cats", "err", err)
+		return nil, err
+	}
+
+	page := &SpyCatPage{Items: cats}
+	if len(cats) > limit {
+		page.Items = cats[:limit]
+		last := &page.Items[limit-1]
+		page.NextCursor = Cursor{
+			Sort:  opts.sortKey(),
+			Value: SpyCatSortValue(last, opts.Sort),
+			ID:    last.ID,
+		}.Encode()
+	}
+
+	logger.Info("Spy cats listed successfully", "count", len(page.Items))
+	return page, nil
 }
 
 func (s *spyCatService) GetSpyCat(ctx context.Context, id string) (*entity.SpyCat, error) {
-	s.logger.Info("Fetching spy cat", "id", id)
+	logger := s.loggerFor(ctx)
+	logger.Info("Fetching spy cat", "id", id)
 
 	cat, err := s.storages.SpyCat.GetSpyCat(ctx, id)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, ErrGetSpyCatNotFound
-		}
-		s.logger.Error("Failed to get spy cat", "err", err)
+		logger.Error("Failed to get spy cat", "err", err)
 		return nil, err
 	}
+	if cat == nil {
+		return nil, ErrGetSpyCatNotFound
+	}
 
-	s.logger.Info("Spy cat fetched successfully", "cat", cat)
+	logger.Info("Spy cat fetched successfully", "cat", cat)
 	return cat, nil
 }
+
+func (s *spyCatService) ListSpyCatAssignments(ctx context.Context, id string) ([]entity.Assignment, error) {
+	logger := s.loggerFor(ctx)
+	logger.Info("Listing spy cat assignments", "id", id)
+
+	cat, err := s.storages.SpyCat.GetSpyCat(ctx, id)
+	if err != nil {
+		logger.Error("Failed to get spy cat", "err", err)
+		return nil, err
+	}
+	if cat == nil {
+		return nil, ErrListSpyCatAssignmentsNotFound
+	}
+
+	assignments, err := s.storages.Assignment.ListSpyCatAssignments(ctx, id)
+	if err != nil {
+		logger.Error("Failed to list spy cat assignments", "err", err)
+		return nil, err
+	}
+
+	logger.Info("Spy cat assignments listed successfully", "count", len(assignments))
+	return assignments, nil
+}
@@ -2,32 +2,77 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/Kontentski/develops-today-task/internal/entity"
 )
 
+// Storages provides a collection of storage interfaces.
 type Storages struct {
-	SpyCat  SpyCatStorage
-	Mission MissionStorage
-	Target  TargetStorage
+	SpyCat       SpyCatStorage
+	Mission      MissionStorage
+	Target       TargetStorage
+	SalaryChange SalaryChangeStorage
+	Assignment   AssignmentStorage
+	Breed        BreedStorage
+	APIKey       APIKeyStorage
+	Idempotency  IdempotencyKeyStorage
+	Transactor   Transactor
+}
+
+// Transactor runs storage operations atomically.
+type Transactor interface {
+	// WithTx calls fn with storages bound to a transaction. The transaction
+	// is committed if fn returns nil and rolled back otherwise.
+	WithTx(ctx context.Context, fn func(tx Storages) error) error
+}
+
+// WithTx runs fn in a transaction, all storage operations inside fn must be done via tx.
+// Storages without Transactor run fn directly.
+func (s Storages) WithTx(ctx context.Context, fn func(tx Storages) error) error {
+	if s.Transactor == nil {
+		return fn(s)
+	}
+	return s.Transactor.WithTx(ctx, fn)
 }
 
 // SpyCatStorage defines storage operations for SpyCat.
 type SpyCatStorage interface {
 	GetSpyCat(ctx context.Context, id string) (*entity.SpyCat, error)
+	// GetSpyCatForUpdate returns the spy cat like GetSpyCat and locks it until the transaction ends.
+	GetSpyCatForUpdate(ctx context.Context, id string) (*entity.SpyCat, error)
 	CreateSpyCat(ctx context.Context, cat *entity.SpyCat) (*entity.SpyCat, error)
 	UpdateSpyCat(ctx context.Context, cat *entity.SpyCat) (*entity.SpyCat, error)
+	// SetSpyCatSalary updates only the salary of the spy cat.
+	SetSpyCatSalary(ctx context.Context, id string, salary float64) error
+	// AssignSpyCatMission sets the mission of the spy cat unless it already has one in a single
+	// conditional update, so concurrent assignments claim the cat once. It reports whether the cat was claimed.
+	AssignSpyCatMission(ctx context.Context, id, missionID string) (bool, error)
+	// ReleaseSpyCatMission clears the mission of the spy cat if it is still passed one.
+	ReleaseSpyCatMission(ctx context.Context, id, missionID string) error
 	DeleteSpyCat(ctx context.Context, id string) error
-	ListSpyCats(ctx context.Context) ([]entity.SpyCat, error)
+	ListSpyCats(ctx context.Context, opts ListSpyCatsOptions) ([]entity.SpyCat, error)
+	// ListEmployedSpyCats returns spy cats, including deleted ones, employed at any moment of [from, to).
+	ListEmployedSpyCats(ctx context.Context, from, to time.Time) ([]entity.SpyCat, error)
+	// UpdateSpyCatsBreed copies the breed name and profile to its spy cats and returns their number.
+	// Spy cats without breed ID are matched by the breed name in any case.
+	UpdateSpyCatsBreed(ctx context.Context, breed *entity.Breed) (int, error)
 }
 
 // MissionStorage defines storage operations for Mission.
 type MissionStorage interface {
 	GetMission(ctx context.Context, id string) (*entity.Mission, error)
 	CreateMission(ctx context.Context, mission *entity.Mission) (*entity.Mission, error)
+	// GetMissionForUpdate returns the mission like GetMission and locks it until the transaction ends.
+	GetMissionForUpdate(ctx context.Context, id string) (*entity.Mission, error)
 	UpdateMission(ctx context.Context, mission *entity.Mission) (*entity.Mission, error)
+	// UpdateMissionState updates only the spy cat, status and completion time of the mission.
+	UpdateMissionState(ctx context.Context, mission *entity.Mission) error
 	DeleteMission(ctx context.Context, id string) error
-	ListMissions(ctx context.Context) ([]entity.Mission, error)
+	ListMissions(ctx context.Context, opts ListMissionsOptions) ([]entity.Mission, error)
+	ListMissionSummaries(ctx context.Context, opts ListMissionsOptions) ([]entity.MissionSummary, error)
+	// CountCompletedMissions returns the number of missions completed in [from, to) by spy cat ID.
+	CountCompletedMissions(ctx context.Context, from, to time.Time) (map[string]int, error)
 }
 
 // TargetStorage defines storage operations for Target.
@@ -38,3 +83,67 @@ type TargetStorage interface {
 	DeleteTarget(ctx context.Context, id string) error
 	ListTargets(ctx context.Context, missionID string) ([]entity.Target, error)
 }
+
+// SalaryChangeStorage defines storage operations for SalaryChange.
+type SalaryChangeStorage interface {
+	CreateSalaryChange(ctx context.Context, change *entity.SalaryChange) (*entity.SalaryChange, error)
+	UpdateSalaryChange(ctx context.Context, change *entity.SalaryChange) (*entity.SalaryChange, error)
+	// ClaimSalaryChange marks a not applied change as applied in a single conditional update,
+	// so concurrent jobs apply it once. It reports whether the change was claimed.
+	ClaimSalaryChange(ctx context.Context, id string, appliedAt time.Time) (bool, error)
+	// ListSalaryChanges returns all changes of the spy cat ordered by effective date.
+	ListSalaryChanges(ctx context.Context, spyCatID string) ([]entity.SalaryChange, error)
+	// ListSpyCatsSalaryChanges returns changes of passed spy cats effective before passed time.
+	ListSpyCatsSalaryChanges(ctx context.Context, spyCatIDs []string, before time.Time) ([]entity.SalaryChange, error)
+	// ListPendingSalaryChanges returns not applied changes effective before passed time.
+	ListPendingSalaryChanges(ctx context.Context, before time.Time) ([]entity.SalaryChange, error)
+}
+
+// AssignmentStorage defines storage operations for Assignment.
+type AssignmentStorage interface {
+	CreateAssignment(ctx context.Context, assignment *entity.Assignment) (*entity.Assignment, error)
+	UpdateAssignment(ctx context.Context, assignment *entity.Assignment) (*entity.Assignment, error)
+	// GetActiveAssignment returns not ended assignment of the mission.
+	GetActiveAssignment(ctx context.Context, missionID string) (*entity.Assignment, error)
+	ListMissionAssignments(ctx context.Context, missionID string) ([]entity.Assignment, error)
+	ListSpyCatAssignments(ctx context.Context, spyCatID string) ([]entity.Assignment, error)
+}
+
+// BreedStorage defines storage operations for Breed.
+type BreedStorage interface {
+	GetBreed(ctx context.Context, id string) (*entity.Breed, error)
+	CreateBreed(ctx context.Context, breed *entity.Breed) (*entity.Breed, error)
+	// ListBreeds returns breeds matching passed options ordered by name.
+	ListBreeds(ctx context.Context, opts ListBreedsOptions) ([]entity.Breed, error)
+	// UpsertBreeds creates passed breeds and overwrites existing breeds with the same IDs.
+	UpsertBreeds(ctx context.Context, breeds []entity.Breed) error
+	CreateBreedSync(ctx context.Context, sync *entity.BreedSync) (*entity.BreedSync, error)
+	// GetLastBreedSync returns the latest sync, nil if breeds were never synced.
+	GetLastBreedSync(ctx context.Context) (*entity.BreedSync, error)
+	// GetBreedStats aggregates spy cats of the breed without computing MissionCompletionRate.
+	GetBreedStats(ctx context.Context, id string) (*entity.BreedStats, error)
+}
+
+// APIKeyStorage defines storage operations for APIKey.
+type APIKeyStorage interface {
+	GetAPIKey(ctx context.Context, id string) (*entity.APIKey, error)
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error)
+	CreateAPIKey(ctx context.Context, key *entity.APIKey) (*entity.APIKey, error)
+	UpdateAPIKey(ctx context.Context, key *entity.APIKey) (*entity.APIKey, error)
+	// TouchAPIKey sets only the last used time of the key, so it does not overwrite concurrent revocations.
+	TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error
+	// ListAPIKeys returns all keys, including revoked ones, newest first.
+	ListAPIKeys(ctx context.Context) ([]entity.APIKey, error)
+}
+
+// IdempotencyKeyStorage defines storage operations for IdempotencyKey.
+type IdempotencyKeyStorage interface {
+	// GetIdempotencyKey returns the key of the owner, including an expired one.
+	GetIdempotencyKey(ctx context.Context, owner, key string) (*entity.IdempotencyKey, error)
+	// CreateIdempotencyKey returns nil if the owner already has the key, so concurrent requests claim it once.
+	CreateIdempotencyKey(ctx context.Context, key *entity.IdempotencyKey) (*entity.IdempotencyKey, error)
+	UpdateIdempotencyKey(ctx context.Context, key *entity.IdempotencyKey) (*entity.IdempotencyKey, error)
+	DeleteIdempotencyKey(ctx context.Context, id string) error
+	// DeleteExpiredIdempotencyKeys deletes keys expired before passed time and returns their number.
+	DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) (int, error)
+}
@@ -31,18 +31,19 @@ type CreateTargetOptions struct {
 }
 
 func (s *targetService) CreateTarget(ctx context.Context, missionID string, opts CreateTargetOptions) (*entity.Target, error) {
-	s.logger.Info("Creating new target", "missionID", missionID, "opts", opts)
+	logger := s.loggerFor(ctx)
+	logger.Info("Creating new target", "missionID", missionID, "opts", opts)
 
 	mission, err := s.storages.Mission.GetMission(ctx, missionID)
 	if err != nil {
-		s.logger.Error("Failed to get mission", "err", err)
+		logger.Error("Failed to get mission", "err", err)
 		return nil, err
 	}
 	if mission == nil {
 		return nil, ErrCreateTargetMissionNotFound
 	}
 
-	if mission.Completed {
+	if mission.Status.IsFinal() {
 		return nil, ErrCreateTargetCompletedMission
 	}
 
@@ -60,11 +61,11 @@ func (s *targetService) CreateTarget(ctx context.Context, missionID string, opts
 
 	createdTarget, err := s.storage.CreateTarget(ctx, target)
 	if err != nil {
-		s.logger.Error("Failed to create target", "err", err)
+		logger.Error("Failed to create target", "err", err)
 		return nil, err
 	}
 
-	s.logger.Info("Target created successfully", "target", createdTarget)
+	logger.Info("Target created successfully", "target", createdTarget)
 	return createdTarget, nil
 }
 
@@ -74,73 +75,89 @@ type UpdateTargetOptions struct {
 }
 
 func (s *targetService) UpdateTarget(ctx context.Context, id string, opts UpdateTargetOptions) (*entity.Target, error) {
-	s.logger.Info("Updating target", "id", id, "opts", opts)
-
-	target, err := s.storage.GetTarget(ctx, id)
-	if err != nil {
-		s.logger.Error("Failed to get target", "err", err)
-		return nil, err
-	}
-	if target == nil {
-		return nil, ErrUpdateTargetNotFound
-	}
-
-	mission, err := s.storages.Mission.GetMission(ctx, target.MissionID)
-	if err != nil {
-		s.logger.Error("Failed to get mission", "err", err)
-		return nil, err
-	}
+	logger := s.loggerFor(ctx)
+	logger.Info("Updating target", "id", id, "opts", opts)
+
+	var updatedTarget *entity.Target
+	err := s.storages.WithTx(ctx, func(tx Storages) error {
+		target, err := tx.Target.GetTarget(ctx, id)
+		if err != nil {
+			logger.Error("Failed to get target", "err", err)
+			return err
+		}
+		if target == nil {
+			return ErrUpdateTargetNotFound
+		}
 
-	if opts.Notes != nil {
-		if target.Completed {
-			return nil, ErrUpdateTargetCompletedMission
+		mission, err := tx.Mission.GetMissionForUpdate(ctx, target.MissionID)
+		if err != nil {
+			logger.Error("Failed to get mission", "err", err)
+			return err
 		}
-		if mission.Completed {
-			return nil, ErrUpdateTargetCompletedMission
+		if mission == nil {
+			// the mission was deleted
+			return ErrUpdateTargetNotFound
+		}
+		if !canAccessMission(ctx, mission) {
+			logger.Info("Target mission is not assigned to the agent", "id", id)
+			return ErrUpdateTargetForbidden
 		}
-		target.Notes = *opts.Notes
-	}
 
-	// Handle completion update
-	if opts.Completed != nil {
-		target.Completed = *opts.Completed
-
-		// If all targets are completed, mark mission as completed
-		if *opts.Completed {
-			allCompleted := true
-			for _, t := range mission.Targets {
-				// check if all targets are completed except the current one
-				if t.ID != target.ID && !t.Completed {
-					allCompleted = false
-					break
-				}
+		if opts.Notes != nil {
+			if target.Completed {
+				return ErrUpdateTargetCompletedMission
+			}
+			if mission.Status.IsFinal() {
+				return ErrUpdateTargetCompletedMission
 			}
-			if allCompleted {
-				mission.Completed = true
-				if _, err := s.storages.Mission.UpdateMission(ctx, mission); err != nil {
-					s.logger.Error("Failed to update mission completion status", "err", err)
-					return nil, err
+			target.Notes = *opts.Notes
+		}
+
+		// Handle completion update
+		if opts.Completed != nil {
+			target.Completed = *opts.Completed
+
+			// If all targets of a mission in progress are completed, mark mission as completed
+			if *opts.Completed {
+				allCompleted := true
+				for _, t := range mission.Targets {
+					// check if all targets are completed except the current one
+					if t.ID != target.ID && !t.Completed {
+						allCompleted = false
+						break
+					}
+				}
+				if allCompleted && mission.Status.CanTransitionTo(entity.MissionStatusCompleted) {
+					if err := finishMission(ctx, tx, mission, entity.MissionStatusCompleted); err != nil {
+						logger.Error("Failed to update mission completion status", "err", err)
+						return err
+					}
 				}
 			}
 		}
-	}
 
-	updatedTarget, err := s.storage.UpdateTarget(ctx, target)
+		updatedTarget, err = tx.Target.UpdateTarget(ctx, target)
+		if err != nil {
+			logger.Error("Failed to update target", "err", err)
+			return err
+		}
+		return nil
+	})
 	if err != nil {
-		s.logger.Error("Failed to update target", "err", err)
 		return nil, err
 	}
 
-	s.logger.Info("Target updated successfully", "target", updatedTarget)
+	logger.Info("Target updated successfully", "target", updatedTarget)
 	return updatedTarget, nil
 }
 
 func (s *targetService) DeleteTarget(ctx context.Context, id string) error {
-	s.logger.Info("Deleting target", "id", id)
+	logger := s.loggerFor(ctx)
+	logger.Info("Deleting target", "id", id)
 
 	target, err := s.storage.GetTarget(ctx, id)
 	if err != nil {
-		s.logger.Error("Failed to get target", "err", err)
+		logger.Error("Failed to get target", "err", err)
 		return err
 	}
 	if target == nil {
@@ -152,23 +169,24 @@ func (s *targetService) DeleteTarget(ctx context.Context, id string) error {
 	}
 
 	if err := s.storage.DeleteTarget(ctx, id); err != nil {
-		s.logger.Error("Failed to delete target", "err", err)
+		logger.Error("Failed to delete target", "err", err)
 		return err
 	}
 
-	s.logger.Info("Target deleted successfully", "id", id)
+	logger.Info("Target deleted successfully", "id", id)
 	return nil
 }
 
 func (s *targetService) ListTargets(ctx context.Context, missionID string) ([]entity.Target, error) {
-	s.logger.Info("Listing targets for mission", "missionID", missionID)
+	logger := s.loggerFor(ctx)
+	logger.Info("Listing targets for mission", "missionID", missionID)
 
 	targets, err := s.storage.ListTargets(ctx, missionID)
 	if err != nil {
-		s.logger.Error("Failed to list targets", "err", err)
+		logger.Error("Failed to list targets", "err", err)
 		return nil, err
 	}
 
-	s.logger.Info("Targets listed successfully", "count", len(targets))
+	logger.Info("Targets listed successfully", "count", len(targets))
 	return targets, nil
 }
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Kontentski/develops-today-task/internal/entity"
+	"github.com/Kontentski/develops-today-task/internal/service"
+	"github.com/Kontentski/develops-today-task/pkg/postgresql"
+)
+
+var _ service.APIKeyStorage = (*apiKeyStorage)(nil)
+
+type apiKeyStorage struct {
+	*postgresql.PostgreSQLGorm
+}
+
+func NewAPIKeyStorage(postgresql *postgresql.PostgreSQLGorm) *apiKeyStorage {
+	return &apiKeyStorage{postgresql}
+}
+
+func (s *apiKeyStorage) GetAPIKey(ctx context.Context, id string) (*entity.APIKey, error) {
+	return s.getAPIKey(ctx, "id = ?", id)
+}
+
+func (s *apiKeyStorage) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error) {
+	return s.getAPIKey(ctx, "prefix = ?", prefix)
+}
+
+func (s *apiKeyStorage) getAPIKey(ctx context.Context, query string, args ...interface{}) (*entity.APIKey, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	var key entity.APIKey
+	err := db.Where(query, args...).First(&key).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", postgresql.Error(err))
+	}
+	return &key, nil
+}
+
+func (s *apiKeyStorage) CreateAPIKey(ctx context.Context, key *entity.APIKey) (*entity.APIKey, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	err := db.Create(key).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to create api key: %w", postgresql.Error(err))
+	}
+	return key, nil
+}
+
+func (s *apiKeyStorage) UpdateAPIKey(ctx context.Context, key *entity.APIKey) (*entity.APIKey, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	err := db.Save(key).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to update api key: %w", postgresql.Error(err))
+	}
+	return key, nil
+}
+
+func (s *apiKeyStorage) TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	err := db.Model(&entity.APIKey{}).Where("id = ?", id).UpdateColumn("last_used_at", usedAt).Error
+	if err != nil {
+		return fmt.Errorf("failed to touch api key: %w", postgresql.Error(err))
+	}
+	return nil
+}
+
+func (s *apiKeyStorage) ListAPIKeys(ctx context.Context) ([]entity.APIKey, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	var keys []entity.APIKey
+	err := db.Order("created_at DESC, id DESC").Find(&keys).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", postgresql.Error(err))
+	}
+	return keys, nil
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/Kontentski/develops-today-task/internal/entity"
+	"github.com/Kontentski/develops-today-task/internal/service"
+	"github.com/Kontentski/develops-today-task/pkg/postgresql"
+)
+
+var _ service.AssignmentStorage = (*assignmentStorage)(nil)
+
+type assignmentStorage struct {
+	*postgresql.PostgreSQLGorm
+}
+
+func NewAssignmentStorage(postgresql *postgresql.PostgreSQLGorm) *assignmentStorage {
+	return &assignmentStorage{postgresql}
+}
+
+func (s *assignmentStorage) CreateAssignment(ctx context.Context, assignment *entity.Assignment) (*entity.Assignment, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	err := db.Create(assignment).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to create assignment: %w", postgresql.Error(err))
+	}
+	return assignment, nil
+}
+
+func (s *assignmentStorage) UpdateAssignment(ctx context.Context, assignment *entity.Assignment) (*entity.Assignment, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	err := db.Save(assignment).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to update assignment: %w", postgresql.Error(err))
+	}
+	return assignment, nil
+}
+
+func (s *assignmentStorage) GetActiveAssignment(ctx context.Context, missionID string) (*entity.Assignment, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	var assignment entity.Assignment
+	err := db.
+		Where("mission_id = ? AND ended_at IS NULL", missionID).
+		Order("started_at DESC").
+		First(&assignment).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get active assignment: %w", postgresql.Error(err))
+	}
+	return &assignment, nil
+}
+
+func (s *assignmentStorage) ListMissionAssignments(ctx context.Context, missionID string) ([]entity.Assignment, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	var assignments []entity.Assignment
+	err := db.
+		Where("mission_id = ?", missionID).
+		Order("started_at DESC").
+		Find(&assignments).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mission assignments: %w", postgresql.Error(err))
+	}
+	return assignments, nil
+}
+
+func (s *assignmentStorage) ListSpyCatAssignments(ctx context.Context, spyCatID string) ([]entity.Assignment, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	var assignments []entity.Assignment
+	err := db.
+		Where("spy_cat_id = ?", spyCatID).
+		Order("started_at DESC").
+		Find(&assignments).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list spy cat assignments: %w", postgresql.Error(err))
+	}
+	return assignments, nil
+}
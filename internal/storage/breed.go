@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Kontentski/develops-today-task/internal/entity"
+	"github.com/Kontentski/develops-today-task/internal/service"
+	"github.com/Kontentski/develops-today-task/pkg/postgresql"
+)
+
+var _ service.BreedStorage = (*breedStorage)(nil)
+
+type breedStorage struct {
+	*postgresql.PostgreSQLGorm
+}
+
+func NewBreedStorage(postgresql *postgresql.PostgreSQLGorm) *breedStorage {
+	return &breedStorage{postgresql}
+}
+
+func (s *breedStorage) GetBreed(ctx context.Context, id string) (*entity.Breed, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	var breed entity.Breed
+	err := db.First(&breed, "id = ?", id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get breed: %w", postgresql.Error(err))
+	}
+	return &breed, nil
+}
+
+func (s *breedStorage) CreateBreed(ctx context.Context, breed *entity.Breed) (*entity.Breed, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	err := db.Create(breed).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to create breed: %w", postgresql.Error(err))
+	}
+	return breed, nil
+}
+
+func (s *breedStorage) ListBreeds(ctx context.Context, opts service.ListBreedsOptions) ([]entity.Breed, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	query := db.Model(&entity.Breed{})
+	if opts.Name != "" {
+		query = query.Where("name ILIKE ?", containsPattern(opts.Name))
+	}
+	if opts.Origin != "" {
+		query = query.Where("origin ILIKE ?", containsPattern(opts.Origin))
+	}
+
+	var breeds []entity.Breed
+	err := query.Order("name, id").Find(&breeds).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list breeds: %w", postgresql.Error(err))
+	}
+	return breeds, nil
+}
+
+func (s *breedStorage) UpsertBreeds(ctx context.Context, breeds []entity.Breed) error {
+	if len(breeds) == 0 {
+		return nil
+	}
+
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "origin", "temperament", "description", "custom", "updated_at"}),
+	}).Create(&breeds).Error
+	if err != nil {
+		return fmt.Errorf("failed to upsert breeds: %w", postgresql.Error(err))
+	}
+	return nil
+}
+
+func (s *breedStorage) CreateBreedSync(ctx context.Context, sync *entity.BreedSync) (*entity.BreedSync, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	err := db.Create(sync).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to create breed sync: %w", postgresql.Error(err))
+	}
+	return sync, nil
+}
+
+func (s *breedStorage) GetLastBreedSync(ctx context.Context) (*entity.BreedSync, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	var sync entity.BreedSync
+	err := db.Order("synced_at DESC").First(&sync).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get last breed sync: %w", postgresql.Error(err))
+	}
+	return &sync, nil
+}
+
+func (s *breedStorage) GetBreedStats(ctx context.Context, id string) (*entity.BreedStats, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	var cats struct {
+		ActiveCats        int
+		AverageSalary     float64
+		AverageExperience float64
+	}
+	err := db.
+		Model(&entity.SpyCat{}).
+		Select("COUNT(*) AS active_cats, COALESCE(AVG(salary), 0) AS average_salary, COALESCE(AVG(years_of_experience), 0) AS average_experience").
+		Where("breed_id = ?", id).
+		Scan(&cats).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get breed cat stats: %w", postgresql.Error(err))
+	}
+
+	// missions of deleted cats count too, so spy_cats are joined without the soft delete filter
+	var missions struct {
+		CompletedMissions int
+		FinishedMissions  int
+	}
+	err = db.
+		Model(&entity.Mission{}).
+		Select("COUNT(*) FILTER (WHERE missions.status = ?) AS completed_missions, COUNT(*) AS finished_missions", entity.MissionStatusCompleted).
+		Joins("JOIN spy_cats ON spy_cats.id = missions.spy_cat_id").
+		Where("spy_cats.breed_id = ? AND missions.status IN ?", id, []entity.MissionStatus{entity.MissionStatusCompleted, entity.MissionStatusAborted}).
+		Scan(&missions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get breed mission stats: %w", postgresql.Error(err))
+	}
+
+	return &entity.BreedStats{
+		BreedID:           id,
+		ActiveCats:        cats.ActiveCats,
+		AverageSalary:     cats.AverageSalary,
+		AverageExperience: cats.AverageExperience,
+		CompletedMissions: missions.CompletedMissions,
+		FinishedMissions:  missions.FinishedMissions,
+	}, nil
+}
+
+// containsPattern returns an ILIKE pattern matching values containing passed text literally.
+func containsPattern(text string) string {
+	return "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(text) + "%"
+}
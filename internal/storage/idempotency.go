@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Kontentski/develops-today-task/internal/entity"
+	"github.com/Kontentski/develops-today-task/internal/service"
+	"github.com/Kontentski/develops-today-task/pkg/postgresql"
+)
+
+var _ service.IdempotencyKeyStorage = (*idempotencyKeyStorage)(nil)
+
+type idempotencyKeyStorage struct {
+	*postgresql.PostgreSQLGorm
+}
+
+func NewIdempotencyKeyStorage(postgresql *postgresql.PostgreSQLGorm) *idempotencyKeyStorage {
+	return &idempotencyKeyStorage{postgresql}
+}
+
+func (s *idempotencyKeyStorage) GetIdempotencyKey(ctx context.Context, owner, key string) (*entity.IdempotencyKey, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	var idempotencyKey entity.IdempotencyKey
+	err := db.Where("owner = ? AND key = ?", owner, key).First(&idempotencyKey).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get idempotency key: %w", postgresql.Error(err))
+	}
+	return &idempotencyKey, nil
+}
+
+func (s *idempotencyKeyStorage) CreateIdempotencyKey(ctx context.Context, key *entity.IdempotencyKey) (*entity.IdempotencyKey, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	result := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "owner"}, {Name: "key"}},
+		DoNothing: true,
+	}).Create(key)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to create idempotency key: %w", postgresql.Error(result.Error))
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return key, nil
+}
+
+func (s *idempotencyKeyStorage) UpdateIdempotencyKey(ctx context.Context, key *entity.IdempotencyKey) (*entity.IdempotencyKey, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	err := db.Save(key).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to update idempotency key: %w", postgresql.Error(err))
+	}
+	return key, nil
+}
+
+func (s *idempotencyKeyStorage) DeleteIdempotencyKey(ctx context.Context, id string) error {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	err := db.Where("id = ?", id).Delete(&entity.IdempotencyKey{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete idempotency key: %w", postgresql.Error(err))
+	}
+	return nil
+}
+
+func (s *idempotencyKeyStorage) DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) (int, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	result := db.Where("expires_at < ?", before).Delete(&entity.IdempotencyKey{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", postgresql.Error(result.Error))
+	}
+	return int(result.RowsAffected), nil
+}
@@ -0,0 +1,111 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/Kontentski/develops-today-task/internal/entity"
+	"github.com/Kontentski/develops-today-task/internal/service"
+)
+
+var _ service.APIKeyStorage = (*apiKeyStorage)(nil)
+
+type apiKeyStorage struct {
+	*Store
+}
+
+func NewAPIKeyStorage(store *Store) *apiKeyStorage {
+	return &apiKeyStorage{store}
+}
+
+func (s *apiKeyStorage) GetAPIKey(ctx context.Context, id string) (*entity.APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.data.apiKeys[id]
+	if !ok {
+		return nil, nil
+	}
+	return storedAPIKey(&key), nil
+}
+
+func (s *apiKeyStorage) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, key := range s.data.apiKeys {
+		if key.Prefix == prefix {
+			return storedAPIKey(&key), nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *apiKeyStorage) CreateAPIKey(ctx context.Context, key *entity.APIKey) (*entity.APIKey, error) {
+	defer s.lock()()
+
+	key.ID = newID(key.ID)
+	for _, existing := range s.data.apiKeys {
+		if existing.ID == key.ID || existing.Prefix == key.Prefix {
+			return nil, fmt.Errorf("failed to create api key: duplicate id %s or prefix %s", key.ID, key.Prefix)
+		}
+	}
+	key.CreatedAt = now()
+	key.UpdatedAt = key.CreatedAt
+
+	s.data.apiKeys[key.ID] = *storedAPIKey(key)
+	return key, nil
+}
+
+func (s *apiKeyStorage) UpdateAPIKey(ctx context.Context, key *entity.APIKey) (*entity.APIKey, error) {
+	defer s.lock()()
+
+	key.ID = newID(key.ID)
+	key.UpdatedAt = now()
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = key.UpdatedAt
+	}
+
+	s.data.apiKeys[key.ID] = *storedAPIKey(key)
+	return key, nil
+}
+
+func (s *apiKeyStorage) TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error {
+	defer s.lock()()
+
+	key, ok := s.data.apiKeys[id]
+	if !ok {
+		return nil
+	}
+	key.LastUsedAt = &usedAt
+	s.data.apiKeys[id] = key
+	return nil
+}
+
+func (s *apiKeyStorage) ListAPIKeys(ctx context.Context) ([]entity.APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]entity.APIKey, 0, len(s.data.apiKeys))
+	for _, key := range s.data.apiKeys {
+		keys = append(keys, *storedAPIKey(&key))
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.After(keys[j].CreatedAt)
+		}
+		return keys[i].ID > keys[j].ID
+	})
+	return keys, nil
+}
+
+// storedAPIKey returns a copy of the key not sharing scopes with the original.
+func storedAPIKey(key *entity.APIKey) *entity.APIKey {
+	stored := *key
+	stored.Scopes = slices.Clone(key.Scopes)
+	return &stored
+}
@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Kontentski/develops-today-task/internal/entity"
+	"github.com/Kontentski/develops-today-task/internal/service"
+)
+
+var _ service.AssignmentStorage = (*assignmentStorage)(nil)
+
+type assignmentStorage struct {
+	*Store
+}
+
+func NewAssignmentStorage(store *Store) *assignmentStorage {
+	return &assignmentStorage{store}
+}
+
+func (s *assignmentStorage) CreateAssignment(ctx context.Context, assignment *entity.Assignment) (*entity.Assignment, error) {
+	defer s.lock()()
+
+	assignment.ID = newID(assignment.ID)
+	if _, ok := s.data.assignments[assignment.ID]; ok {
+		return nil, fmt.Errorf("failed to create assignment: duplicate id %s", assignment.ID)
+	}
+	assignment.CreatedAt = now()
+	assignment.UpdatedAt = assignment.CreatedAt
+
+	s.data.assignments[assignment.ID] = *assignment
+	return assignment, nil
+}
+
+func (s *assignmentStorage) UpdateAssignment(ctx context.Context, assignment *entity.Assignment) (*entity.Assignment, error) {
+	defer s.lock()()
+
+	assignment.ID = newID(assignment.ID)
+	assignment.UpdatedAt = now()
+	if assignment.CreatedAt.IsZero() {
+		assignment.CreatedAt = assignment.UpdatedAt
+	}
+
+	s.data.assignments[assignment.ID] = *assignment
+	return assignment, nil
+}
+
+func (s *assignmentStorage) GetActiveAssignment(ctx context.Context, missionID string) (*entity.Assignment, error) {
+	assignments := s.listAssignments(func(assignment *entity.Assignment) bool {
+		return assignment.MissionID == missionID && assignment.EndedAt == nil
+	})
+	if len(assignments) == 0 {
+		return nil, nil
+	}
+	return &assignments[0], nil
+}
+
+func (s *assignmentStorage) ListMissionAssignments(ctx context.Context, missionID string) ([]entity.Assignment, error) {
+	return s.listAssignments(func(assignment *entity.Assignment) bool {
+		return assignment.MissionID == missionID
+	}), nil
+}
+
+func (s *assignmentStorage) ListSpyCatAssignments(ctx context.Context, spyCatID string) ([]entity.Assignment, error) {
+	return s.listAssignments(func(assignment *entity.Assignment) bool {
+		return assignment.SpyCatID == spyCatID
+	}), nil
+}
+
+// listAssignments returns assignments matching the filter, most recent first.
+func (s *assignmentStorage) listAssignments(match func(assignment *entity.Assignment) bool) []entity.Assignment {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	assignments := []entity.Assignment{}
+	for _, assignment := range s.data.assignments {
+		if match(&assignment) {
+			assignments = append(assignments, assignment)
+		}
+	}
+
+	sort.Slice(assignments, func(i, j int) bool {
+		return assignments[i].StartedAt.After(assignments[j].StartedAt)
+	})
+	return assignments
+}
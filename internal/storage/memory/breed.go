@@ -0,0 +1,147 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Kontentski/develops-today-task/internal/entity"
+	"github.com/Kontentski/develops-today-task/internal/service"
+)
+
+var _ service.BreedStorage = (*breedStorage)(nil)
+
+type breedStorage struct {
+	*Store
+}
+
+func NewBreedStorage(store *Store) *breedStorage {
+	return &breedStorage{store}
+}
+
+func (s *breedStorage) GetBreed(ctx context.Context, id string) (*entity.Breed, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	breed, ok := s.data.breeds[id]
+	if !ok {
+		return nil, nil
+	}
+	return &breed, nil
+}
+
+func (s *breedStorage) CreateBreed(ctx context.Context, breed *entity.Breed) (*entity.Breed, error) {
+	defer s.lock()()
+
+	if _, ok := s.data.breeds[breed.ID]; ok {
+		return nil, fmt.Errorf("failed to create breed: duplicate id %s", breed.ID)
+	}
+	breed.CreatedAt = now()
+	breed.UpdatedAt = breed.CreatedAt
+
+	s.data.breeds[breed.ID] = *breed
+	return breed, nil
+}
+
+func (s *breedStorage) ListBreeds(ctx context.Context, opts service.ListBreedsOptions) ([]entity.Breed, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	breeds := []entity.Breed{}
+	for _, breed := range s.data.breeds {
+		if containsFold(breed.Name, opts.Name) && containsFold(breed.Origin, opts.Origin) {
+			breeds = append(breeds, breed)
+		}
+	}
+
+	sort.Slice(breeds, func(i, j int) bool {
+		if breeds[i].Name != breeds[j].Name {
+			return breeds[i].Name < breeds[j].Name
+		}
+		return breeds[i].ID < breeds[j].ID
+	})
+	return breeds, nil
+}
+
+func (s *breedStorage) UpsertBreeds(ctx context.Context, breeds []entity.Breed) error {
+	defer s.lock()()
+
+	now := now()
+	for _, breed := range breeds {
+		breed.CreatedAt = now
+		if existing, ok := s.data.breeds[breed.ID]; ok {
+			breed.CreatedAt = existing.CreatedAt
+		}
+		breed.UpdatedAt = now
+
+		s.data.breeds[breed.ID] = breed
+	}
+	return nil
+}
+
+func (s *breedStorage) CreateBreedSync(ctx context.Context, sync *entity.BreedSync) (*entity.BreedSync, error) {
+	defer s.lock()()
+
+	sync.ID = newID(sync.ID)
+	if _, ok := s.data.breedSyncs[sync.ID]; ok {
+		return nil, fmt.Errorf("failed to create breed sync: duplicate id %s", sync.ID)
+	}
+
+	s.data.breedSyncs[sync.ID] = *sync
+	return sync, nil
+}
+
+func (s *breedStorage) GetLastBreedSync(ctx context.Context) (*entity.BreedSync, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var last *entity.BreedSync
+	for _, sync := range s.data.breedSyncs {
+		if last == nil || sync.SyncedAt.After(last.SyncedAt) {
+			last = &sync
+		}
+	}
+	return last, nil
+}
+
+func (s *breedStorage) GetBreedStats(ctx context.Context, id string) (*entity.BreedStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stats := entity.BreedStats{BreedID: id}
+	breedCats := map[string]bool{}
+	var salary, experience float64
+	for _, cat := range s.data.spyCats {
+		if cat.BreedID == nil || *cat.BreedID != id {
+			continue
+		}
+		breedCats[cat.ID] = true
+		if cat.DeletedAt.Valid {
+			continue
+		}
+		stats.ActiveCats++
+		salary += cat.Salary
+		experience += float64(cat.YearsOfExperience)
+	}
+	if stats.ActiveCats > 0 {
+		stats.AverageSalary = salary / float64(stats.ActiveCats)
+		stats.AverageExperience = experience / float64(stats.ActiveCats)
+	}
+
+	for _, mission := range s.data.missions {
+		if mission.DeletedAt.Valid || mission.SpyCatID == nil || !breedCats[*mission.SpyCatID] || !mission.Status.IsFinal() {
+			continue
+		}
+		stats.FinishedMissions++
+		if mission.Status == entity.MissionStatusCompleted {
+			stats.CompletedMissions++
+		}
+	}
+	return &stats, nil
+}
+
+// containsFold reports whether s contains substr in any case.
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
@@ -0,0 +1,33 @@
+package memory
+
+import (
+	"cmp"
+	"strings"
+)
+
+// compareOrdered compares two ordered values.
+func compareOrdered[T cmp.Ordered](a, b T) int {
+	return cmp.Compare(a, b)
+}
+
+// afterCursor reports whether a record is after the keyset cursor, given the
+// comparison of its sort value with the cursor value, as (value, id) > (cursor, cursorID)
+// does in SQL, or < for descending order.
+func afterCursor(valueCmp int, id, cursorID string, desc bool) bool {
+	c := valueCmp
+	if c == 0 {
+		c = strings.Compare(id, cursorID)
+	}
+	if desc {
+		return c < 0
+	}
+	return c > 0
+}
+
+// limit returns the first n records, non-positive n means no limit.
+func limit[T any](records []T, n int) []T {
+	if n > 0 && len(records) > n {
+		return records[:n]
+	}
+	return records
+}
@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Kontentski/develops-today-task/internal/entity"
+	"github.com/Kontentski/develops-today-task/internal/service"
+)
+
+var _ service.IdempotencyKeyStorage = (*idempotencyKeyStorage)(nil)
+
+type idempotencyKeyStorage struct {
+	*Store
+}
+
+func NewIdempotencyKeyStorage(store *Store) *idempotencyKeyStorage {
+	return &idempotencyKeyStorage{store}
+}
+
+func (s *idempotencyKeyStorage) GetIdempotencyKey(ctx context.Context, owner, key string) (*entity.IdempotencyKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, idempotencyKey := range s.data.idempotency {
+		if idempotencyKey.Owner == owner && idempotencyKey.Key == key {
+			return storedIdempotencyKey(&idempotencyKey), nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *idempotencyKeyStorage) CreateIdempotencyKey(ctx context.Context, key *entity.IdempotencyKey) (*entity.IdempotencyKey, error) {
+	defer s.lock()()
+
+	key.ID = newID(key.ID)
+	for _, existing := range s.data.idempotency {
+		if existing.ID == key.ID {
+			return nil, fmt.Errorf("failed to create idempotency key: duplicate id %s", key.ID)
+		}
+		if existing.Owner == key.Owner && existing.Key == key.Key {
+			return nil, nil
+		}
+	}
+	key.CreatedAt = now()
+	key.UpdatedAt = key.CreatedAt
+
+	s.data.idempotency[key.ID] = *storedIdempotencyKey(key)
+	return key, nil
+}
+
+func (s *idempotencyKeyStorage) UpdateIdempotencyKey(ctx context.Context, key *entity.IdempotencyKey) (*entity.IdempotencyKey, error) {
+	defer s.lock()()
+
+	key.ID = newID(key.ID)
+	key.UpdatedAt = now()
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = key.UpdatedAt
+	}
+
+	s.data.idempotency[key.ID] = *storedIdempotencyKey(key)
+	return key, nil
+}
+
+func (s *idempotencyKeyStorage) DeleteIdempotencyKey(ctx context.Context, id string) error {
+	defer s.lock()()
+
+	delete(s.data.idempotency, id)
+	return nil
+}
+
+func (s *idempotencyKeyStorage) DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) (int, error) {
+	defer s.lock()()
+
+	deleted := 0
+	for id, key := range s.data.idempotency {
+		if key.ExpiresAt.Before(before) {
+			delete(s.data.idempotency, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// storedIdempotencyKey returns a copy of the key not sharing the response body with the original.
+func storedIdempotencyKey(key *entity.IdempotencyKey) *entity.IdempotencyKey {
+	stored := *key
+	stored.ResponseBody = slices.Clone(key.ResponseBody)
+	return &stored
+}
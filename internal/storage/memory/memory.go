@@ -0,0 +1,160 @@
+// Package memory implements service storages in memory.
+// It mirrors the behavior of the GORM storages: soft deletes, preloading
+// of mission relations and nil results for missing records.
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Kontentski/develops-today-task/internal/entity"
+	"github.com/Kontentski/develops-today-task/internal/service"
+)
+
+var _ service.Transactor = (*transactor)(nil)
+
+// Store keeps all entities in memory. It is safe for concurrent use.
+type Store struct {
+	*state
+	// inTx is set on the store of storages bound to a transaction, which holds txMu.
+	inTx bool
+}
+
+// state is shared by the store and its transactions.
+type state struct {
+	mu sync.RWMutex
+	// txMu serializes transactions and writes made outside of them,
+	// so a rolled back transaction discards only its own writes.
+	txMu sync.Mutex
+	data data
+}
+
+// data holds entities by ID.
+type data struct {
+	spyCats       map[string]entity.SpyCat
+	missions      map[string]entity.Mission
+	targets       map[string]entity.Target
+	salaryChanges map[string]entity.SalaryChange
+	assignments   map[string]entity.Assignment
+	breeds        map[string]entity.Breed
+	breedSyncs    map[string]entity.BreedSync
+	apiKeys       map[string]entity.APIKey
+	idempotency   map[string]entity.IdempotencyKey
+}
+
+// NewStore creates an empty store.
+func NewStore() *Store {
+	return &Store{state: &state{
+		data: data{
+			spyCats:       map[string]entity.SpyCat{},
+			missions:      map[string]entity.Mission{},
+			targets:       map[string]entity.Target{},
+			salaryChanges: map[string]entity.SalaryChange{},
+			assignments:   map[string]entity.Assignment{},
+			breeds:        map[string]entity.Breed{},
+			breedSyncs:    map[string]entity.BreedSync{},
+			apiKeys:       map[string]entity.APIKey{},
+			idempotency:   map[string]entity.IdempotencyKey{},
+		},
+	}}
+}
+
+// lock locks the store for a write and returns the unlock function.
+// Writes made outside of transactions wait for the running transaction to end.
+func (s *Store) lock() func() {
+	if !s.inTx {
+		s.txMu.Lock()
+	}
+	s.mu.Lock()
+	return func() {
+		s.mu.Unlock()
+		if !s.inTx {
+			s.txMu.Unlock()
+		}
+	}
+}
+
+// NewStorages creates all storages backed by passed store.
+func NewStorages(store *Store) service.Storages {
+	storages := newStorages(store)
+	storages.Transactor = &transactor{store}
+	return storages
+}
+
+// newStorages creates storages without transactor, so nested transactions
+// run within the outer one.
+func newStorages(store *Store) service.Storages {
+	return service.Storages{
+		SpyCat:       NewSpyCatStorage(store),
+		Mission:      NewMissionStorage(store),
+		Target:       NewTargetStorage(store),
+		SalaryChange: NewSalaryChangeStorage(store),
+		Assignment:   NewAssignmentStorage(store),
+		Breed:        NewBreedStorage(store),
+		APIKey:       NewAPIKeyStorage(store),
+		Idempotency:  NewIdempotencyKeyStorage(store),
+	}
+}
+
+// transactor implements service.Transactor by restoring a snapshot of the store on failure.
+// Transactions are serialized with each other and with writes made outside of them,
+// so the snapshot holds no writes but those of the transaction. Reads are not blocked.
+type transactor struct {
+	*Store
+}
+
+func (t *transactor) WithTx(ctx context.Context, fn func(tx service.Storages) error) error {
+	t.txMu.Lock()
+	defer t.txMu.Unlock()
+
+	t.mu.RLock()
+	snapshot := t.data.clone()
+	t.mu.RUnlock()
+
+	if err := fn(newStorages(&Store{state: t.state, inTx: true})); err != nil {
+		t.mu.Lock()
+		t.data = snapshot
+		t.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// clone returns a copy of all entity maps.
+func (d data) clone() data {
+	return data{
+		spyCats:       cloneMap(d.spyCats),
+		missions:      cloneMap(d.missions),
+		targets:       cloneMap(d.targets),
+		salaryChanges: cloneMap(d.salaryChanges),
+		assignments:   cloneMap(d.assignments),
+		breeds:        cloneMap(d.breeds),
+		breedSyncs:    cloneMap(d.breedSyncs),
+		apiKeys:       cloneMap(d.apiKeys),
+		idempotency:   cloneMap(d.idempotency),
+	}
+}
+
+func cloneMap[T any](m map[string]T) map[string]T {
+	clone := make(map[string]T, len(m))
+	for k, v := range m {
+		clone[k] = v
+	}
+	return clone
+}
+
+// newID generates an ID for a new record unless it is already set.
+func newID(id string) string {
+	if id != "" {
+		return id
+	}
+	return uuid.NewString()
+}
+
+// now returns current time with the precision of PostgreSQL timestamps.
+func now() time.Time {
+	return time.Now().Truncate(time.Microsecond)
+}
@@ -0,0 +1,272 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Kontentski/develops-today-task/internal/entity"
+	"github.com/Kontentski/develops-today-task/internal/service"
+)
+
+var _ service.MissionStorage = (*missionStorage)(nil)
+
+type missionStorage struct {
+	*Store
+}
+
+func NewMissionStorage(store *Store) *missionStorage {
+	return &missionStorage{store}
+}
+
+func (s *missionStorage) CreateMission(ctx context.Context, mission *entity.Mission) (*entity.Mission, error) {
+	defer s.lock()()
+
+	mission.ID = newID(mission.ID)
+	if _, ok := s.data.missions[mission.ID]; ok {
+		return nil, fmt.Errorf("failed to create mission: duplicate id %s", mission.ID)
+	}
+	mission.CreatedAt = now()
+	mission.UpdatedAt = mission.CreatedAt
+
+	// Targets are created along with the mission, as GORM does for associations.
+	for i := range mission.Targets {
+		target := &mission.Targets[i]
+		target.ID = newID(target.ID)
+		target.MissionID = mission.ID
+		target.CreatedAt = mission.CreatedAt
+		target.UpdatedAt = mission.CreatedAt
+		s.data.targets[target.ID] = storedTarget(target)
+	}
+
+	s.data.missions[mission.ID] = storedMission(mission)
+	return mission, nil
+}
+
+func (s *missionStorage) GetMission(ctx context.Context, id string) (*entity.Mission, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	mission, ok := s.data.missions[id]
+	if !ok || mission.DeletedAt.Valid {
+		return nil, nil
+	}
+	s.preloadSpyCat(&mission)
+	s.preloadTargets(&mission)
+	return &mission, nil
+}
+
+// GetMissionForUpdate needs no lock, memory transactions are serialized.
+func (s *missionStorage) GetMissionForUpdate(ctx context.Context, id string) (*entity.Mission, error) {
+	return s.GetMission(ctx, id)
+}
+
+func (s *missionStorage) UpdateMission(ctx context.Context, mission *entity.Mission) (*entity.Mission, error) {
+	defer s.lock()()
+
+	mission.ID = newID(mission.ID)
+	mission.UpdatedAt = now()
+	if mission.CreatedAt.IsZero() {
+		mission.CreatedAt = mission.UpdatedAt
+	}
+
+	s.data.missions[mission.ID] = storedMission(mission)
+	return mission, nil
+}
+
+func (s *missionStorage) UpdateMissionState(ctx context.Context, mission *entity.Mission) error {
+	defer s.lock()()
+
+	stored, ok := s.data.missions[mission.ID]
+	if !ok || stored.DeletedAt.Valid {
+		return nil
+	}
+
+	mission.UpdatedAt = now()
+	stored.SpyCatID = mission.SpyCatID
+	stored.Status = mission.Status
+	stored.CompletedAt = mission.CompletedAt
+	stored.UpdatedAt = mission.UpdatedAt
+	s.data.missions[mission.ID] = stored
+	return nil
+}
+
+func (s *missionStorage) DeleteMission(ctx context.Context, id string) error {
+	defer s.lock()()
+
+	mission, ok := s.data.missions[id]
+	if !ok || mission.DeletedAt.Valid {
+		return nil
+	}
+
+	mission.DeletedAt = gorm.DeletedAt{Time: now(), Valid: true}
+	s.data.missions[id] = mission
+	return nil
+}
+
+func (s *missionStorage) ListMissions(ctx context.Context, opts service.ListMissionsOptions) ([]entity.Mission, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	missions, err := s.listMissions(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list missions: %w", err)
+	}
+	for i := range missions {
+		if opts.IncludeSpyCat {
+			s.preloadSpyCat(&missions[i])
+		}
+		if opts.IncludeTargets {
+			s.preloadTargets(&missions[i])
+		}
+	}
+	return missions, nil
+}
+
+func (s *missionStorage) ListMissionSummaries(ctx context.Context, opts service.ListMissionsOptions) ([]entity.MissionSummary, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	missions, err := s.listMissions(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mission summaries: %w", err)
+	}
+
+	summaries := make([]entity.MissionSummary, len(missions))
+	for i, mission := range missions {
+		summaries[i] = entity.MissionSummary{
+			ID:        mission.ID,
+			SpyCatID:  mission.SpyCatID,
+			Status:    mission.Status,
+			CreatedAt: mission.CreatedAt,
+			UpdatedAt: mission.UpdatedAt,
+		}
+		for _, target := range s.missionTargets(mission.ID) {
+			summaries[i].TargetCount++
+			if target.Completed {
+				summaries[i].CompletedTargetCount++
+			}
+		}
+	}
+	return summaries, nil
+}
+
+func (s *missionStorage) CountCompletedMissions(ctx context.Context, from, to time.Time) (map[string]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := map[string]int{}
+	for _, mission := range s.data.missions {
+		if mission.DeletedAt.Valid || mission.SpyCatID == nil || mission.CompletedAt == nil {
+			continue
+		}
+		if !mission.CompletedAt.Before(from) && mission.CompletedAt.Before(to) {
+			counts[*mission.SpyCatID]++
+		}
+	}
+	return counts, nil
+}
+
+// listMissions applies filters, keyset cursor, order and limit shared by mission list queries.
+func (s *missionStorage) listMissions(opts service.ListMissionsOptions) ([]entity.Mission, error) {
+	var cursor time.Time
+	if opts.Cursor != nil {
+		var err error
+		cursor, err = time.Parse(time.RFC3339Nano, opts.Cursor.Value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	missions := []entity.Mission{}
+	for _, mission := range s.data.missions {
+		if mission.DeletedAt.Valid || !s.matchMission(&mission, opts) {
+			continue
+		}
+		if opts.Cursor != nil && !afterCursor(mission.CreatedAt.Compare(cursor), mission.ID, opts.Cursor.ID, true) {
+			continue
+		}
+		missions = append(missions, mission)
+	}
+
+	sort.Slice(missions, func(i, j int) bool {
+		if c := missions[i].CreatedAt.Compare(missions[j].CreatedAt); c != 0 {
+			return c > 0
+		}
+		return missions[i].ID > missions[j].ID
+	})
+
+	return limit(missions, opts.Limit), nil
+}
+
+// matchMission reports whether the mission passes list filters.
+func (s *missionStorage) matchMission(mission *entity.Mission, opts service.ListMissionsOptions) bool {
+	switch {
+	case opts.Status != nil && mission.Status != *opts.Status:
+		return false
+	case opts.SpyCatID != "" && (mission.SpyCatID == nil || *mission.SpyCatID != opts.SpyCatID):
+		return false
+	case opts.Unassigned && mission.SpyCatID != nil:
+		return false
+	case opts.CreatedFrom != nil && mission.CreatedAt.Before(*opts.CreatedFrom):
+		return false
+	case opts.CreatedTo != nil && !mission.CreatedAt.Before(*opts.CreatedTo):
+		return false
+	}
+
+	if opts.TargetCountry != "" {
+		for _, target := range s.missionTargets(mission.ID) {
+			if target.Country == opts.TargetCountry {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// preloadSpyCat sets the assigned spy cat of the mission.
+func (s *missionStorage) preloadSpyCat(mission *entity.Mission) {
+	mission.SpyCat = nil
+	if mission.SpyCatID == nil {
+		return
+	}
+	if cat, ok := s.data.spyCats[*mission.SpyCatID]; ok && !cat.DeletedAt.Valid {
+		mission.SpyCat = &cat
+	}
+}
+
+// preloadTargets sets targets of the mission.
+func (s *missionStorage) preloadTargets(mission *entity.Mission) {
+	mission.Targets = s.missionTargets(mission.ID)
+}
+
+// missionTargets returns not deleted targets of the mission ordered by creation time.
+// It must be called with the store locked.
+func (s *Store) missionTargets(missionID string) []entity.Target {
+	targets := []entity.Target{}
+	for _, target := range s.data.targets {
+		if target.MissionID == missionID && !target.DeletedAt.Valid {
+			targets = append(targets, target)
+		}
+	}
+
+	sort.Slice(targets, func(i, j int) bool {
+		if c := targets[i].CreatedAt.Compare(targets[j].CreatedAt); c != 0 {
+			return c < 0
+		}
+		return targets[i].ID < targets[j].ID
+	})
+	return targets
+}
+
+// storedMission returns a copy of the mission without relations.
+func storedMission(mission *entity.Mission) entity.Mission {
+	stored := *mission
+	stored.SpyCat = nil
+	stored.Targets = nil
+	return stored
+}
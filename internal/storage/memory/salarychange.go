@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Kontentski/develops-today-task/internal/entity"
+	"github.com/Kontentski/develops-today-task/internal/service"
+)
+
+var _ service.SalaryChangeStorage = (*salaryChangeStorage)(nil)
+
+type salaryChangeStorage struct {
+	*Store
+}
+
+func NewSalaryChangeStorage(store *Store) *salaryChangeStorage {
+	return &salaryChangeStorage{store}
+}
+
+func (s *salaryChangeStorage) CreateSalaryChange(ctx context.Context, change *entity.SalaryChange) (*entity.SalaryChange, error) {
+	defer s.lock()()
+
+	change.ID = newID(change.ID)
+	if _, ok := s.data.salaryChanges[change.ID]; ok {
+		return nil, fmt.Errorf("failed to create salary change: duplicate id %s", change.ID)
+	}
+	change.CreatedAt = now()
+
+	s.data.salaryChanges[change.ID] = *change
+	return change, nil
+}
+
+func (s *salaryChangeStorage) UpdateSalaryChange(ctx context.Context, change *entity.SalaryChange) (*entity.SalaryChange, error) {
+	defer s.lock()()
+
+	change.ID = newID(change.ID)
+	if change.CreatedAt.IsZero() {
+		change.CreatedAt = now()
+	}
+
+	s.data.salaryChanges[change.ID] = *change
+	return change, nil
+}
+
+func (s *salaryChangeStorage) ClaimSalaryChange(ctx context.Context, id string, appliedAt time.Time) (bool, error) {
+	defer s.lock()()
+
+	change, ok := s.data.salaryChanges[id]
+	if !ok || change.AppliedAt != nil {
+		return false, nil
+	}
+
+	change.AppliedAt = &appliedAt
+	s.data.salaryChanges[id] = change
+	return true, nil
+}
+
+func (s *salaryChangeStorage) ListSalaryChanges(ctx context.Context, spyCatID string) ([]entity.SalaryChange, error) {
+	return s.listSalaryChanges(func(change *entity.SalaryChange) bool {
+		return change.SpyCatID == spyCatID
+	}), nil
+}
+
+func (s *salaryChangeStorage) ListSpyCatsSalaryChanges(ctx context.Context, spyCatIDs []string, before time.Time) ([]entity.SalaryChange, error) {
+	ids := make(map[string]bool, len(spyCatIDs))
+	for _, id := range spyCatIDs {
+		ids[id] = true
+	}
+
+	return s.listSalaryChanges(func(change *entity.SalaryChange) bool {
+		return ids[change.SpyCatID] && change.EffectiveAt.Before(before)
+	}), nil
+}
+
+func (s *salaryChangeStorage) ListPendingSalaryChanges(ctx context.Context, before time.Time) ([]entity.SalaryChange, error) {
+	return s.listSalaryChanges(func(change *entity.SalaryChange) bool {
+		return change.AppliedAt == nil && !change.EffectiveAt.After(before)
+	}), nil
+}
+
+// listSalaryChanges returns changes matching the filter ordered by effective date.
+func (s *salaryChangeStorage) listSalaryChanges(match func(change *entity.SalaryChange) bool) []entity.SalaryChange {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	changes := []entity.SalaryChange{}
+	for _, change := range s.data.salaryChanges {
+		if match(&change) {
+			changes = append(changes, change)
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if c := changes[i].EffectiveAt.Compare(changes[j].EffectiveAt); c != 0 {
+			return c < 0
+		}
+		return changes[i].CreatedAt.Before(changes[j].CreatedAt)
+	})
+	return changes
+}
@@ -0,0 +1,277 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Kontentski/develops-today-task/internal/entity"
+	"github.com/Kontentski/develops-today-task/internal/service"
+)
+
+var _ service.SpyCatStorage = (*spyCatStorage)(nil)
+
+type spyCatStorage struct {
+	*Store
+}
+
+func NewSpyCatStorage(store *Store) *spyCatStorage {
+	return &spyCatStorage{store}
+}
+
+func (s *spyCatStorage) CreateSpyCat(ctx context.Context, cat *entity.SpyCat) (*entity.SpyCat, error) {
+	defer s.lock()()
+
+	cat.ID = newID(cat.ID)
+	if _, ok := s.data.spyCats[cat.ID]; ok {
+		return nil, fmt.Errorf("failed to create spy cat: duplicate id %s", cat.ID)
+	}
+	if !s.breedExists(cat.BreedID) {
+		return nil, fmt.Errorf("failed to create spy cat: unknown breed %s", *cat.BreedID)
+	}
+	cat.CreatedAt = now()
+	cat.UpdatedAt = cat.CreatedAt
+
+	s.data.spyCats[cat.ID] = storedSpyCat(cat)
+	return cat, nil
+}
+
+func (s *spyCatStorage) UpdateSpyCat(ctx context.Context, cat *entity.SpyCat) (*entity.SpyCat, error) {
+	defer s.lock()()
+
+	if !s.breedExists(cat.BreedID) {
+		return nil, fmt.Errorf("failed to update spy cat: unknown breed %s", *cat.BreedID)
+	}
+
+	cat.ID = newID(cat.ID)
+	cat.UpdatedAt = now()
+	if cat.CreatedAt.IsZero() {
+		cat.CreatedAt = cat.UpdatedAt
+	}
+
+	s.data.spyCats[cat.ID] = storedSpyCat(cat)
+	return cat, nil
+}
+
+func (s *spyCatStorage) SetSpyCatSalary(ctx context.Context, id string, salary float64) error {
+	defer s.lock()()
+
+	cat, ok := s.data.spyCats[id]
+	if !ok || cat.DeletedAt.Valid {
+		return nil
+	}
+
+	cat.Salary = salary
+	cat.UpdatedAt = now()
+	s.data.spyCats[id] = cat
+	return nil
+}
+
+func (s *spyCatStorage) AssignSpyCatMission(ctx context.Context, id, missionID string) (bool, error) {
+	defer s.lock()()
+
+	cat, ok := s.data.spyCats[id]
+	if !ok || cat.DeletedAt.Valid || cat.MissionID != nil {
+		return false, nil
+	}
+
+	cat.MissionID = &missionID
+	cat.UpdatedAt = now()
+	s.data.spyCats[id] = cat
+	return true, nil
+}
+
+func (s *spyCatStorage) ReleaseSpyCatMission(ctx context.Context, id, missionID string) error {
+	defer s.lock()()
+
+	cat, ok := s.data.spyCats[id]
+	if !ok || cat.DeletedAt.Valid || cat.MissionID == nil || *cat.MissionID != missionID {
+		return nil
+	}
+
+	cat.MissionID = nil
+	cat.UpdatedAt = now()
+	s.data.spyCats[id] = cat
+	return nil
+}
+
+func (s *spyCatStorage) DeleteSpyCat(ctx context.Context, id string) error {
+	defer s.lock()()
+
+	cat, ok := s.data.spyCats[id]
+	if !ok || cat.DeletedAt.Valid {
+		return nil
+	}
+
+	cat.DeletedAt = gorm.DeletedAt{Time: now(), Valid: true}
+	s.data.spyCats[id] = cat
+	return nil
+}
+
+func (s *spyCatStorage) ListSpyCats(ctx context.Context, opts service.ListSpyCatsOptions) ([]entity.SpyCat, error) {
+	var cursor interface{}
+	if opts.Cursor != nil {
+		var err error
+		cursor, err = service.ParseSpyCatSortValue(opts.Sort, opts.Cursor.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list spy cats: %w", err)
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	cats := []entity.SpyCat{}
+	for _, cat := range s.data.spyCats {
+		if cat.DeletedAt.Valid || !matchSpyCat(&cat, opts) {
+			continue
+		}
+		if opts.Cursor != nil && !afterCursor(compareSpyCatSortValue(&cat, cursor), cat.ID, opts.Cursor.ID, opts.Desc) {
+			continue
+		}
+		cats = append(cats, cat)
+	}
+
+	sort.Slice(cats, func(i, j int) bool {
+		c := compareSpyCats(&cats[i], &cats[j], opts.Sort)
+		if c == 0 {
+			c = strings.Compare(cats[i].ID, cats[j].ID)
+		}
+		if opts.Desc {
+			return c > 0
+		}
+		return c < 0
+	})
+
+	return limit(cats, opts.Limit), nil
+}
+
+func (s *spyCatStorage) ListEmployedSpyCats(ctx context.Context, from, to time.Time) ([]entity.SpyCat, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	cats := []entity.SpyCat{}
+	for _, cat := range s.data.spyCats {
+		if cat.CreatedAt.Before(to) && (!cat.DeletedAt.Valid || !cat.DeletedAt.Time.Before(from)) {
+			cats = append(cats, cat)
+		}
+	}
+
+	sort.Slice(cats, func(i, j int) bool {
+		if cats[i].Name != cats[j].Name {
+			return cats[i].Name < cats[j].Name
+		}
+		return cats[i].ID < cats[j].ID
+	})
+	return cats, nil
+}
+
+func (s *spyCatStorage) GetSpyCat(ctx context.Context, id string) (*entity.SpyCat, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	cat, ok := s.data.spyCats[id]
+	if !ok || cat.DeletedAt.Valid {
+		return nil, nil
+	}
+	return &cat, nil
+}
+
+// GetSpyCatForUpdate needs no lock, memory transactions are serialized.
+func (s *spyCatStorage) GetSpyCatForUpdate(ctx context.Context, id string) (*entity.SpyCat, error) {
+	return s.GetSpyCat(ctx, id)
+}
+
+func (s *spyCatStorage) UpdateSpyCatsBreed(ctx context.Context, breed *entity.Breed) (int, error) {
+	defer s.lock()()
+
+	updated := 0
+	for id, cat := range s.data.spyCats {
+		if cat.DeletedAt.Valid {
+			continue
+		}
+		if cat.BreedID != nil && *cat.BreedID != breed.ID {
+			continue
+		}
+		if cat.BreedID == nil && !strings.EqualFold(cat.Breed, breed.Name) {
+			continue
+		}
+
+		breedID := breed.ID
+		cat.Breed = breed.Name
+		cat.BreedID = &breedID
+		cat.BreedProfile = breed.Profile()
+		cat.UpdatedAt = now()
+		s.data.spyCats[id] = cat
+		updated++
+	}
+	return updated, nil
+}
+
+// breedExists reports whether the breed referenced by spy cat exists, cats may have no breed.
+func (s *spyCatStorage) breedExists(breedID *string) bool {
+	if breedID == nil {
+		return true
+	}
+	_, ok := s.data.breeds[*breedID]
+	return ok
+}
+
+// storedSpyCat returns a copy of the spy cat without relations.
+func storedSpyCat(cat *entity.SpyCat) entity.SpyCat {
+	stored := *cat
+	stored.Mission = nil
+	return stored
+}
+
+// matchSpyCat reports whether the spy cat passes list filters.
+func matchSpyCat(cat *entity.SpyCat, opts service.ListSpyCatsOptions) bool {
+	switch {
+	case opts.Breed != "" && cat.Breed != opts.Breed:
+		return false
+	case opts.MinExperience != nil && cat.YearsOfExperience < *opts.MinExperience:
+		return false
+	case opts.MaxExperience != nil && cat.YearsOfExperience > *opts.MaxExperience:
+		return false
+	case opts.MinSalary != nil && cat.Salary < *opts.MinSalary:
+		return false
+	case opts.MaxSalary != nil && cat.Salary > *opts.MaxSalary:
+		return false
+	case opts.Available != nil && *opts.Available != (cat.MissionID == nil):
+		return false
+	}
+	return true
+}
+
+// compareSpyCats compares sort field values of two spy cats.
+func compareSpyCats(a, b *entity.SpyCat, sort service.SpyCatSort) int {
+	switch sort {
+	case service.SpyCatSortName:
+		return strings.Compare(a.Name, b.Name)
+	case service.SpyCatSortExperience:
+		return compareOrdered(a.YearsOfExperience, b.YearsOfExperience)
+	case service.SpyCatSortSalary:
+		return compareOrdered(a.Salary, b.Salary)
+	default:
+		return a.CreatedAt.Compare(b.CreatedAt)
+	}
+}
+
+// compareSpyCatSortValue compares sort field value of the spy cat with a value parsed from a cursor.
+func compareSpyCatSortValue(cat *entity.SpyCat, value interface{}) int {
+	switch v := value.(type) {
+	case string:
+		return strings.Compare(cat.Name, v)
+	case int:
+		return compareOrdered(cat.YearsOfExperience, v)
+	case float64:
+		return compareOrdered(cat.Salary, v)
+	case time.Time:
+		return cat.CreatedAt.Compare(v)
+	}
+	return 0
+}
@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/Kontentski/develops-today-task/internal/entity"
+	"github.com/Kontentski/develops-today-task/internal/service"
+)
+
+var _ service.TargetStorage = (*targetStorage)(nil)
+
+type targetStorage struct {
+	*Store
+}
+
+func NewTargetStorage(store *Store) *targetStorage {
+	return &targetStorage{store}
+}
+
+func (s *targetStorage) GetTarget(ctx context.Context, id string) (*entity.Target, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	target, ok := s.data.targets[id]
+	if !ok || target.DeletedAt.Valid {
+		return nil, nil
+	}
+	return &target, nil
+}
+
+func (s *targetStorage) CreateTarget(ctx context.Context, target *entity.Target) (*entity.Target, error) {
+	defer s.lock()()
+
+	target.ID = newID(target.ID)
+	if _, ok := s.data.targets[target.ID]; ok {
+		return nil, fmt.Errorf("failed to create target: duplicate id %s", target.ID)
+	}
+	if _, ok := s.data.missions[target.MissionID]; !ok {
+		return nil, fmt.Errorf("failed to create target: mission %s does not exist", target.MissionID)
+	}
+	target.CreatedAt = now()
+	target.UpdatedAt = target.CreatedAt
+
+	s.data.targets[target.ID] = storedTarget(target)
+	return target, nil
+}
+
+func (s *targetStorage) UpdateTarget(ctx context.Context, target *entity.Target) (*entity.Target, error) {
+	defer s.lock()()
+
+	target.ID = newID(target.ID)
+	target.UpdatedAt = now()
+	if target.CreatedAt.IsZero() {
+		target.CreatedAt = target.UpdatedAt
+	}
+
+	s.data.targets[target.ID] = storedTarget(target)
+	return target, nil
+}
+
+func (s *targetStorage) DeleteTarget(ctx context.Context, id string) error {
+	defer s.lock()()
+
+	target, ok := s.data.targets[id]
+	if !ok || target.DeletedAt.Valid {
+		return nil
+	}
+
+	target.DeletedAt = gorm.DeletedAt{Time: now(), Valid: true}
+	s.data.targets[id] = target
+	return nil
+}
+
+func (s *targetStorage) ListTargets(ctx context.Context, missionID string) ([]entity.Target, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.missionTargets(missionID), nil
+}
+
+// storedTarget returns a copy of the target without relations.
+func storedTarget(target *entity.Target) entity.Target {
+	stored := *target
+	stored.Mission = nil
+	return stored
+}
@@ -3,8 +3,10 @@ package storage
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/Kontentski/develops-today-task/internal/entity"
 	"github.com/Kontentski/develops-today-task/internal/service"
@@ -22,16 +24,35 @@ func NewMissionStorage(postgresql *postgresql.PostgreSQLGorm) *missionStorage {
 }
 
 func (s *missionStorage) CreateMission(ctx context.Context, mission *entity.Mission) (*entity.Mission, error) {
-	err := s.DB.Create(mission).Error
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	err := db.Create(mission).Error
 	if err != nil {
-		return nil, fmt.Errorf("failed to create mission: %w", err)
+		return nil, fmt.Errorf("failed to create mission: %w", postgresql.Error(err))
 	}
 	return mission, nil
 }
 
 func (s *missionStorage) GetMission(ctx context.Context, id string) (*entity.Mission, error) {
+	return s.getMission(ctx, id, false)
+}
+
+func (s *missionStorage) GetMissionForUpdate(ctx context.Context, id string) (*entity.Mission, error) {
+	return s.getMission(ctx, id, true)
+}
+
+// getMission returns the mission with its relations, the mission row is locked with forUpdate.
+func (s *missionStorage) getMission(ctx context.Context, id string, forUpdate bool) (*entity.Mission, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	if forUpdate {
+		db = db.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+
 	var mission entity.Mission
-	err := s.DB.
+	err := db.
 		Preload("SpyCat").
 		Preload("Targets").
 		First(&mission, "id = ?", id).Error
@@ -39,35 +60,148 @@ func (s *missionStorage) GetMission(ctx context.Context, id string) (*entity.Mis
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
 		}
-		return nil, fmt.Errorf("failed to get mission: %w", err)
+		return nil, fmt.Errorf("failed to get mission: %w", postgresql.Error(err))
 	}
 	return &mission, nil
 }
 
 func (s *missionStorage) UpdateMission(ctx context.Context, mission *entity.Mission) (*entity.Mission, error) {
-	err := s.DB.Save(mission).Error
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	err := db.Save(mission).Error
 	if err != nil {
-		return nil, fmt.Errorf("failed to update mission: %w", err)
+		return nil, fmt.Errorf("failed to update mission: %w", postgresql.Error(err))
 	}
 	return mission, nil
 }
 
+func (s *missionStorage) UpdateMissionState(ctx context.Context, mission *entity.Mission) error {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	err := db.
+		Model(mission).
+		Select("spy_cat_id", "status", "completed_at", "updated_at").
+		Updates(mission).Error
+	if err != nil {
+		return fmt.Errorf("failed to update mission state: %w", postgresql.Error(err))
+	}
+	return nil
+}
+
 func (s *missionStorage) DeleteMission(ctx context.Context, id string) error {
-	err := s.DB.Where("id = ?", id).Delete(&entity.Mission{}).Error
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	err := db.Where("id = ?", id).Delete(&entity.Mission{}).Error
 	if err != nil {
-		return fmt.Errorf("failed to delete mission: %w", err)
+		return fmt.Errorf("failed to delete mission: %w", postgresql.Error(err))
 	}
 	return nil
 }
 
-func (s *missionStorage) ListMissions(ctx context.Context) ([]entity.Mission, error) {
+func (s *missionStorage) ListMissions(ctx context.Context, opts service.ListMissionsOptions) ([]entity.Mission, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	query, err := missionListQuery(db.Model(&entity.Mission{}), opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list missions: %w", postgresql.Error(err))
+	}
+	if opts.IncludeSpyCat {
+		query = query.Preload("SpyCat")
+	}
+	if opts.IncludeTargets {
+		query = query.Preload("Targets")
+	}
+
 	var missions []entity.Mission
-	err := s.DB.
-		Preload("SpyCat").
-		Preload("Targets").
-		Find(&missions).Error
+	err = query.Find(&missions).Error
 	if err != nil {
-		return nil, fmt.Errorf("failed to list missions: %w", err)
+		return nil, fmt.Errorf("failed to list missions: %w", postgresql.Error(err))
 	}
 	return missions, nil
 }
+
+func (s *missionStorage) ListMissionSummaries(ctx context.Context, opts service.ListMissionsOptions) ([]entity.MissionSummary, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	query, err := missionListQuery(db.Model(&entity.Mission{}), opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mission summaries: %w", postgresql.Error(err))
+	}
+
+	var summaries []entity.MissionSummary
+	err = query.
+		Select(`missions.id, missions.spy_cat_id, missions.status, missions.created_at, missions.updated_at,
+			(SELECT COUNT(*) FROM targets WHERE targets.mission_id = missions.id AND targets.deleted_at IS NULL) AS target_count,
+			(SELECT COUNT(*) FROM targets WHERE targets.mission_id = missions.id AND targets.deleted_at IS NULL AND targets.completed) AS completed_target_count`).
+		Scan(&summaries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mission summaries: %w", postgresql.Error(err))
+	}
+	return summaries, nil
+}
+
+func (s *missionStorage) CountCompletedMissions(ctx context.Context, from, to time.Time) (map[string]int, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	var rows []struct {
+		SpyCatID string
+		Count    int
+	}
+	err := db.
+		Model(&entity.Mission{}).
+		Select("spy_cat_id, COUNT(*) AS count").
+		Where("spy_cat_id IS NOT NULL AND completed_at >= ? AND completed_at < ?", from, to).
+		Group("spy_cat_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count completed missions: %w", postgresql.Error(err))
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.SpyCatID] = row.Count
+	}
+	return counts, nil
+}
+
+// missionListQuery applies filters, keyset cursor, order and limit shared by mission list queries.
+func missionListQuery(query *gorm.DB, opts service.ListMissionsOptions) (*gorm.DB, error) {
+	if opts.Status != nil {
+		query = query.Where("missions.status = ?", *opts.Status)
+	}
+	if opts.SpyCatID != "" {
+		query = query.Where("missions.spy_cat_id = ?", opts.SpyCatID)
+	}
+	if opts.Unassigned {
+		query = query.Where("missions.spy_cat_id IS NULL")
+	}
+	if opts.TargetCountry != "" {
+		query = query.Where(
+			"EXISTS (SELECT 1 FROM targets WHERE targets.mission_id = missions.id AND targets.deleted_at IS NULL AND targets.country = ?)",
+			opts.TargetCountry,
+		)
+	}
+	if opts.CreatedFrom != nil {
+		query = query.Where("missions.created_at >= ?", *opts.CreatedFrom)
+	}
+	if opts.CreatedTo != nil {
+		query = query.Where("missions.created_at < ?", *opts.CreatedTo)
+	}
+	if opts.Cursor != nil {
+		createdAt, err := time.Parse(time.RFC3339Nano, opts.Cursor.Value)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("(missions.created_at, missions.id) < (?, ?)", createdAt, opts.Cursor.ID)
+	}
+
+	return query.
+		Order("missions.created_at DESC, missions.id DESC").
+		Limit(opts.Limit), nil
+}
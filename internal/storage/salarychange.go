@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Kontentski/develops-today-task/internal/entity"
+	"github.com/Kontentski/develops-today-task/internal/service"
+	"github.com/Kontentski/develops-today-task/pkg/postgresql"
+)
+
+var _ service.SalaryChangeStorage = (*salaryChangeStorage)(nil)
+
+type salaryChangeStorage struct {
+	*postgresql.PostgreSQLGorm
+}
+
+func NewSalaryChangeStorage(postgresql *postgresql.PostgreSQLGorm) *salaryChangeStorage {
+	return &salaryChangeStorage{postgresql}
+}
+
+func (s *salaryChangeStorage) CreateSalaryChange(ctx context.Context, change *entity.SalaryChange) (*entity.SalaryChange, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	err := db.Create(change).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to create salary change: %w", postgresql.Error(err))
+	}
+	return change, nil
+}
+
+func (s *salaryChangeStorage) UpdateSalaryChange(ctx context.Context, change *entity.SalaryChange) (*entity.SalaryChange, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	err := db.Save(change).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to update salary change: %w", postgresql.Error(err))
+	}
+	return change, nil
+}
+
+func (s *salaryChangeStorage) ClaimSalaryChange(ctx context.Context, id string, appliedAt time.Time) (bool, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	result := db.
+		Model(&entity.SalaryChange{}).
+		Where("id = ? AND applied_at IS NULL", id).
+		Update("applied_at", appliedAt)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to claim salary change: %w", postgresql.Error(result.Error))
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (s *salaryChangeStorage) ListSalaryChanges(ctx context.Context, spyCatID string) ([]entity.SalaryChange, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	var changes []entity.SalaryChange
+	err := db.
+		Where("spy_cat_id = ?", spyCatID).
+		Order("effective_at, created_at").
+		Find(&changes).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list salary changes: %w", postgresql.Error(err))
+	}
+	return changes, nil
+}
+
+func (s *salaryChangeStorage) ListSpyCatsSalaryChanges(ctx context.Context, spyCatIDs []string, before time.Time) ([]entity.SalaryChange, error) {
+	var changes []entity.SalaryChange
+	if len(spyCatIDs) == 0 {
+		return changes, nil
+	}
+
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	err := db.
+		Where("spy_cat_id IN ? AND effective_at < ?", spyCatIDs, before).
+		Order("effective_at, created_at").
+		Find(&changes).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list spy cats salary changes: %w", postgresql.Error(err))
+	}
+	return changes, nil
+}
+
+func (s *salaryChangeStorage) ListPendingSalaryChanges(ctx context.Context, before time.Time) ([]entity.SalaryChange, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	var changes []entity.SalaryChange
+	err := db.
+		Where("applied_at IS NULL AND effective_at <= ?", before).
+		Order("effective_at, created_at").
+		Find(&changes).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending salary changes: %w", postgresql.Error(err))
+	}
+	return changes, nil
+}
@@ -3,8 +3,10 @@ package storage
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/Kontentski/develops-today-task/internal/entity"
 	"github.com/Kontentski/develops-today-task/internal/service"
@@ -22,46 +24,208 @@ func NewSpyCatStorage(postgresql *postgresql.PostgreSQLGorm) *spyCatStorage {
 }
 
 func (s *spyCatStorage) CreateSpyCat(ctx context.Context, cat *entity.SpyCat) (*entity.SpyCat, error) {
-	err := s.DB.Create(cat).Error
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	err := db.Create(cat).Error
 	if err != nil {
-		return nil, fmt.Errorf("failed to create spy cat: %w", err)
+		return nil, fmt.Errorf("failed to create spy cat: %w", postgresql.Error(err))
 	}
 	return cat, nil
 }
 
 func (s *spyCatStorage) UpdateSpyCat(ctx context.Context, cat *entity.SpyCat) (*entity.SpyCat, error) {
-	err := s.DB.Save(cat).Error
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	err := db.Save(cat).Error
 	if err != nil {
-		return nil, fmt.Errorf("failed to update spy cat: %w", err)
+		return nil, fmt.Errorf("failed to update spy cat: %w", postgresql.Error(err))
 	}
 	return cat, nil
 }
 
+func (s *spyCatStorage) SetSpyCatSalary(ctx context.Context, id string, salary float64) error {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	err := db.Model(&entity.SpyCat{}).Where("id = ?", id).Update("salary", salary).Error
+	if err != nil {
+		return fmt.Errorf("failed to set spy cat salary: %w", postgresql.Error(err))
+	}
+	return nil
+}
+
+func (s *spyCatStorage) AssignSpyCatMission(ctx context.Context, id, missionID string) (bool, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	result := db.
+		Model(&entity.SpyCat{}).
+		Where("id = ? AND mission_id IS NULL", id).
+		Update("mission_id", missionID)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to assign spy cat mission: %w", postgresql.Error(result.Error))
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (s *spyCatStorage) ReleaseSpyCatMission(ctx context.Context, id, missionID string) error {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	err := db.
+		Model(&entity.SpyCat{}).
+		Where("id = ? AND mission_id = ?", id, missionID).
+		Update("mission_id", nil).Error
+	if err != nil {
+		return fmt.Errorf("failed to release spy cat mission: %w", postgresql.Error(err))
+	}
+	return nil
+}
+
 func (s *spyCatStorage) DeleteSpyCat(ctx context.Context, id string) error {
-	err := s.DB.Where("id = ?", id).Delete(&entity.SpyCat{}).Error
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	err := db.Where("id = ?", id).Delete(&entity.SpyCat{}).Error
 	if err != nil {
-		return fmt.Errorf("failed to delete spy cat: %w", err)
+		return fmt.Errorf("failed to delete spy cat: %w", postgresql.Error(err))
 	}
 	return nil
 }
 
-func (s *spyCatStorage) ListSpyCats(ctx context.Context) ([]entity.SpyCat, error) {
+// spyCatSortColumns maps sort fields to table columns.
+var spyCatSortColumns = map[service.SpyCatSort]string{
+	service.SpyCatSortCreatedAt:  "created_at",
+	service.SpyCatSortName:       "name",
+	service.SpyCatSortExperience: "years_of_experience",
+	service.SpyCatSortSalary:     "salary",
+}
+
+func (s *spyCatStorage) ListSpyCats(ctx context.Context, opts service.ListSpyCatsOptions) ([]entity.SpyCat, error) {
+	column, ok := spyCatSortColumns[opts.Sort]
+	if !ok {
+		return nil, fmt.Errorf("failed to list spy cats: unknown sort field %q", opts.Sort)
+	}
+
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	query := db.Model(&entity.SpyCat{})
+	if opts.Breed != "" {
+		query = query.Where("breed = ?", opts.Breed)
+	}
+	if opts.MinExperience != nil {
+		query = query.Where("years_of_experience >= ?", *opts.MinExperience)
+	}
+	if opts.MaxExperience != nil {
+		query = query.Where("years_of_experience <= ?", *opts.MaxExperience)
+	}
+	if opts.MinSalary != nil {
+		query = query.Where("salary >= ?", *opts.MinSalary)
+	}
+	if opts.MaxSalary != nil {
+		query = query.Where("salary <= ?", *opts.MaxSalary)
+	}
+	if opts.Available != nil {
+		if *opts.Available {
+			query = query.Where("mission_id IS NULL")
+		} else {
+			query = query.Where("mission_id IS NOT NULL")
+		}
+	}
+
+	direction := "ASC"
+	comparison := ">"
+	if opts.Desc {
+		direction = "DESC"
+		comparison = "<"
+	}
+
+	if opts.Cursor != nil {
+		value, err := service.ParseSpyCatSortValue(opts.Sort, opts.Cursor.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list spy cats: %w", postgresql.Error(err))
+		}
+		query = query.Where(
+			fmt.Sprintf("(%s, id) %s (?, ?)", column, comparison),
+			value, opts.Cursor.ID,
+		)
+	}
+
 	var cats []entity.SpyCat
-	err := s.DB.Find(&cats).Error
+	err := query.
+		Order(fmt.Sprintf("%s %s, id %s", column, direction, direction)).
+		Limit(opts.Limit).
+		Find(&cats).Error
 	if err != nil {
-		return nil, fmt.Errorf("failed to list spy cats: %w", err)
+		return nil, fmt.Errorf("failed to list spy cats: %w", postgresql.Error(err))
+	}
+	return cats, nil
+}
+
+func (s *spyCatStorage) ListEmployedSpyCats(ctx context.Context, from, to time.Time) ([]entity.SpyCat, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	var cats []entity.SpyCat
+	err := db.
+		Unscoped().
+		Where("created_at < ? AND (deleted_at IS NULL OR deleted_at >= ?)", to, from).
+		Order("name, id").
+		Find(&cats).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list employed spy cats: %w", postgresql.Error(err))
 	}
 	return cats, nil
 }
 
 func (s *spyCatStorage) GetSpyCat(ctx context.Context, id string) (*entity.SpyCat, error) {
+	return s.getSpyCat(ctx, id, false)
+}
+
+func (s *spyCatStorage) GetSpyCatForUpdate(ctx context.Context, id string) (*entity.SpyCat, error) {
+	return s.getSpyCat(ctx, id, true)
+}
+
+// getSpyCat returns the spy cat, its row is locked with forUpdate.
+func (s *spyCatStorage) getSpyCat(ctx context.Context, id string, forUpdate bool) (*entity.SpyCat, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	if forUpdate {
+		db = db.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+
 	var cat entity.SpyCat
-	err := s.DB.First(&cat, "id = ?", id).Error
+	err := db.First(&cat, "id = ?", id).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
 		}
-		return nil, fmt.Errorf("failed to get spy cat: %w", err)
+		return nil, fmt.Errorf("failed to get spy cat: %w", postgresql.Error(err))
 	}
 	return &cat, nil
 }
+
+func (s *spyCatStorage) UpdateSpyCatsBreed(ctx context.Context, breed *entity.Breed) (int, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	profile := breed.Profile()
+	result := db.
+		Model(&entity.SpyCat{}).
+		Where("breed_id = ? OR (breed_id IS NULL AND LOWER(breed) = LOWER(?))", breed.ID, breed.Name).
+		Updates(map[string]interface{}{
+			"breed":             breed.Name,
+			"breed_id":          breed.ID,
+			"breed_origin":      profile.Origin,
+			"breed_temperament": profile.Temperament,
+			"breed_description": profile.Description,
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to update spy cats breed: %w", postgresql.Error(result.Error))
+	}
+	return int(result.RowsAffected), nil
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/Kontentski/develops-today-task/internal/service"
+	"github.com/Kontentski/develops-today-task/pkg/postgresql"
+)
+
+var _ service.Transactor = (*transactor)(nil)
+
+// NewStorages creates all storages bound to passed connection.
+func NewStorages(postgresql *postgresql.PostgreSQLGorm) service.Storages {
+	return service.Storages{
+		SpyCat:       NewSpyCatStorage(postgresql),
+		Mission:      NewMissionStorage(postgresql),
+		Target:       NewTargetStorage(postgresql),
+		SalaryChange: NewSalaryChangeStorage(postgresql),
+		Assignment:   NewAssignmentStorage(postgresql),
+		Breed:        NewBreedStorage(postgresql),
+		APIKey:       NewAPIKeyStorage(postgresql),
+		Idempotency:  NewIdempotencyKeyStorage(postgresql),
+		Transactor:   &transactor{postgresql},
+	}
+}
+
+// transactor implements service.Transactor using GORM transactions.
+type transactor struct {
+	*postgresql.PostgreSQLGorm
+}
+
+func (t *transactor) WithTx(ctx context.Context, fn func(tx service.Storages) error) error {
+	return postgresql.Error(t.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(NewStorages(&postgresql.PostgreSQLGorm{
+			DB:               tx,
+			StatementTimeout: t.StatementTimeout,
+		}))
+	}))
+}
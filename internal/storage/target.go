@@ -22,46 +22,61 @@ func NewTargetStorage(postgresql *postgresql.PostgreSQLGorm) *targetStorage {
 }
 
 func (s *targetStorage) GetTarget(ctx context.Context, id string) (*entity.Target, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
 	var target entity.Target
-	err := s.DB.First(&target, "id = ?", id).Error
+	err := db.First(&target, "id = ?", id).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
 		}
-		return nil, fmt.Errorf("failed to get target: %w", err)
+		return nil, fmt.Errorf("failed to get target: %w", postgresql.Error(err))
 	}
 	return &target, nil
 }
 
 func (s *targetStorage) CreateTarget(ctx context.Context, target *entity.Target) (*entity.Target, error) {
-	err := s.DB.Create(target).Error
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	err := db.Create(target).Error
 	if err != nil {
-		return nil, fmt.Errorf("failed to create target: %w", err)
+		return nil, fmt.Errorf("failed to create target: %w", postgresql.Error(err))
 	}
 	return target, nil
 }
 
 func (s *targetStorage) UpdateTarget(ctx context.Context, target *entity.Target) (*entity.Target, error) {
-	err := s.DB.Save(target).Error
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	err := db.Save(target).Error
 	if err != nil {
-		return nil, fmt.Errorf("failed to update target: %w", err)
+		return nil, fmt.Errorf("failed to update target: %w", postgresql.Error(err))
 	}
 	return target, nil
 }
 
 func (s *targetStorage) DeleteTarget(ctx context.Context, id string) error {
-	err := s.DB.Where("id = ?", id).Delete(&entity.Target{}).Error
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
+	err := db.Where("id = ?", id).Delete(&entity.Target{}).Error
 	if err != nil {
-		return fmt.Errorf("failed to delete target: %w", err)
+		return fmt.Errorf("failed to delete target: %w", postgresql.Error(err))
 	}
 	return nil
 }
 
 func (s *targetStorage) ListTargets(ctx context.Context, missionID string) ([]entity.Target, error) {
+	db, cancel := s.WithContext(ctx)
+	defer cancel()
+
 	var targets []entity.Target
-	err := s.DB.Where("mission_id = ?", missionID).Find(&targets).Error
+	err := db.Where("mission_id = ?", missionID).Find(&targets).Error
 	if err != nil {
-		return nil, fmt.Errorf("failed to list targets: %w", err)
+		return nil, fmt.Errorf("failed to list targets: %w", postgresql.Error(err))
 	}
 	return targets, nil
 }
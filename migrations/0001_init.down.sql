@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS targets;
+ALTER TABLE IF EXISTS spy_cats DROP CONSTRAINT IF EXISTS fk_spy_cats_mission;
+DROP TABLE IF EXISTS missions;
+DROP TABLE IF EXISTS spy_cats;
@@ -0,0 +1,48 @@
+-- Schema previously created by GORM AutoMigrate, IF NOT EXISTS keeps it a no-op for such databases.
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+CREATE TABLE IF NOT EXISTS spy_cats (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name text,
+    years_of_experience bigint,
+    breed text,
+    salary decimal,
+    mission_id uuid,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_spy_cats_created_at ON spy_cats (created_at);
+CREATE INDEX IF NOT EXISTS idx_spy_cats_deleted_at ON spy_cats (deleted_at);
+
+CREATE TABLE IF NOT EXISTS missions (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    spy_cat_id uuid CONSTRAINT fk_missions_spy_cat REFERENCES spy_cats (id),
+    completed boolean,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_missions_created_at ON missions (created_at);
+CREATE INDEX IF NOT EXISTS idx_missions_deleted_at ON missions (deleted_at);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_spy_cats_mission') THEN
+        ALTER TABLE spy_cats ADD CONSTRAINT fk_spy_cats_mission FOREIGN KEY (mission_id) REFERENCES missions (id);
+    END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS targets (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    mission_id uuid NOT NULL CONSTRAINT fk_missions_targets REFERENCES missions (id) ON UPDATE CASCADE ON DELETE SET NULL,
+    name text,
+    country text,
+    notes text,
+    completed boolean,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_targets_created_at ON targets (created_at);
+CREATE INDEX IF NOT EXISTS idx_targets_deleted_at ON targets (deleted_at);
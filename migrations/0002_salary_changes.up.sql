@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS salary_changes (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    spy_cat_id uuid NOT NULL,
+    old_salary decimal,
+    new_salary decimal,
+    effective_at timestamptz NOT NULL,
+    reason text,
+    actor text,
+    applied_at timestamptz,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_salary_changes_spy_cat_id ON salary_changes (spy_cat_id);
+CREATE INDEX IF NOT EXISTS idx_salary_changes_effective_at ON salary_changes (effective_at);
@@ -0,0 +1,5 @@
+ALTER TABLE missions ADD COLUMN completed boolean;
+UPDATE missions SET completed = status = 'completed';
+
+ALTER TABLE missions DROP COLUMN status;
+ALTER TABLE missions DROP COLUMN completed_at;
@@ -0,0 +1,23 @@
+-- Replaces missions.completed flag with the status lifecycle.
+ALTER TABLE missions ADD COLUMN IF NOT EXISTS status varchar(16) NOT NULL DEFAULT 'draft';
+ALTER TABLE missions ADD COLUMN IF NOT EXISTS completed_at timestamptz;
+CREATE INDEX IF NOT EXISTS idx_missions_status ON missions (status);
+CREATE INDEX IF NOT EXISTS idx_missions_completed_at ON missions (completed_at);
+
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = 'missions' AND column_name = 'completed'
+    ) THEN
+        UPDATE missions SET
+            status = CASE
+                WHEN completed THEN 'completed'
+                WHEN spy_cat_id IS NOT NULL THEN 'assigned'
+                ELSE 'draft'
+            END,
+            completed_at = CASE WHEN completed THEN COALESCE(completed_at, updated_at) END;
+
+        ALTER TABLE missions DROP COLUMN completed;
+    END IF;
+END $$;
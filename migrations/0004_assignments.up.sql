@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS assignments (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    spy_cat_id uuid NOT NULL,
+    mission_id uuid NOT NULL,
+    started_at timestamptz NOT NULL,
+    ended_at timestamptz,
+    reason text,
+    end_reason text,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_assignments_spy_cat_id ON assignments (spy_cat_id);
+CREATE INDEX IF NOT EXISTS idx_assignments_mission_id ON assignments (mission_id);
@@ -0,0 +1,2 @@
+-- TheCatAPI ID of the spy cat breed, empty for cats created before breeds were resolved.
+ALTER TABLE spy_cats ADD COLUMN IF NOT EXISTS breed_id text NOT NULL DEFAULT '';
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_spy_cats_breed_id;
+ALTER TABLE spy_cats DROP CONSTRAINT IF EXISTS fk_spy_cats_breed;
+
+UPDATE spy_cats SET breed_id = '' WHERE breed_id IS NULL;
+ALTER TABLE spy_cats ALTER COLUMN breed_id SET DEFAULT '';
+ALTER TABLE spy_cats ALTER COLUMN breed_id SET NOT NULL;
+
+DROP TABLE IF EXISTS breed_syncs;
+DROP TABLE IF EXISTS breeds;
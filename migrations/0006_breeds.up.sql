@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS breeds (
+    id text PRIMARY KEY,
+    name text NOT NULL,
+    origin text,
+    temperament text,
+    description text,
+    custom boolean NOT NULL DEFAULT false,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS breed_syncs (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    count bigint,
+    synced_at timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_breed_syncs_synced_at ON breed_syncs (synced_at);
+
+-- cats created before breeds were resolved have no breed ID
+ALTER TABLE spy_cats ALTER COLUMN breed_id DROP NOT NULL;
+ALTER TABLE spy_cats ALTER COLUMN breed_id DROP DEFAULT;
+UPDATE spy_cats SET breed_id = NULL WHERE breed_id = '';
+
+-- keep breeds of existing cats until the first sync replaces them
+INSERT INTO breeds (id, name, created_at, updated_at)
+SELECT DISTINCT ON (breed_id) breed_id, breed, now(), now()
+FROM spy_cats
+WHERE breed_id IS NOT NULL
+ORDER BY breed_id, created_at
+ON CONFLICT (id) DO NOTHING;
+
+ALTER TABLE spy_cats
+    ADD CONSTRAINT fk_spy_cats_breed FOREIGN KEY (breed_id) REFERENCES breeds (id);
+CREATE INDEX IF NOT EXISTS idx_spy_cats_breed_id ON spy_cats (breed_id);
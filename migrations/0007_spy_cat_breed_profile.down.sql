@@ -0,0 +1,3 @@
+ALTER TABLE spy_cats DROP COLUMN IF EXISTS breed_description;
+ALTER TABLE spy_cats DROP COLUMN IF EXISTS breed_temperament;
+ALTER TABLE spy_cats DROP COLUMN IF EXISTS breed_origin;
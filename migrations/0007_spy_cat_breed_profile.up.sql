@@ -0,0 +1,10 @@
+ALTER TABLE spy_cats ADD COLUMN IF NOT EXISTS breed_origin text NOT NULL DEFAULT '';
+ALTER TABLE spy_cats ADD COLUMN IF NOT EXISTS breed_temperament text NOT NULL DEFAULT '';
+ALTER TABLE spy_cats ADD COLUMN IF NOT EXISTS breed_description text NOT NULL DEFAULT '';
+
+UPDATE spy_cats
+SET breed_origin = COALESCE(breeds.origin, ''),
+    breed_temperament = COALESCE(breeds.temperament, ''),
+    breed_description = COALESCE(breeds.description, '')
+FROM breeds
+WHERE breeds.id = spy_cats.breed_id;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    owner text NOT NULL,
+    key text NOT NULL,
+    fingerprint text NOT NULL,
+    response_status bigint,
+    response_content_type text,
+    response_body bytea,
+    expires_at timestamptz NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_idempotency_keys_owner_key ON idempotency_keys (owner, key);
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
@@ -0,0 +1,2 @@
+-- backfilled spy cat missions are kept, they are maintained by assignments since then
+DELETE FROM assignments WHERE reason = 'assigned before assignment history';
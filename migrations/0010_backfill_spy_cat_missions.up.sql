@@ -0,0 +1,17 @@
+-- spy_cats.mission_id marks busy cats, but it was not written before, so cats on active
+-- missions are marked now. A cat on several active missions is marked with the latest one.
+UPDATE spy_cats SET mission_id = active.id
+FROM (
+    SELECT DISTINCT ON (spy_cat_id) id, spy_cat_id
+    FROM missions
+    WHERE spy_cat_id IS NOT NULL AND status NOT IN ('completed', 'aborted') AND deleted_at IS NULL
+    ORDER BY spy_cat_id, created_at DESC
+) active
+WHERE active.spy_cat_id = spy_cats.id AND spy_cats.mission_id IS NULL;
+
+-- missions assigned before the assignment history get an open assignment
+INSERT INTO assignments (spy_cat_id, mission_id, started_at, reason, created_at, updated_at)
+SELECT m.spy_cat_id, m.id, COALESCE(m.updated_at, m.created_at, now()), 'assigned before assignment history', now(), now()
+FROM missions m
+WHERE m.spy_cat_id IS NOT NULL AND m.status NOT IN ('completed', 'aborted') AND m.deleted_at IS NULL
+    AND NOT EXISTS (SELECT 1 FROM assignments a WHERE a.mission_id = m.id AND a.ended_at IS NULL);
@@ -0,0 +1,9 @@
+// Package migrations embeds versioned SQL migrations of the database schema.
+package migrations
+
+import "embed"
+
+// FS contains <version>_<name>.up.sql and <version>_<name>.down.sql files.
+//
+//go:embed *.sql
+var FS embed.FS
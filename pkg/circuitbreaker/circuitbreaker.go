@@ -0,0 +1,94 @@
+// Package circuitbreaker stops calls to a failing dependency for a cooldown period.
+package circuitbreaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned by Allow while calls must fail fast.
+var ErrOpen = errors.New("circuit breaker is open")
+
+type state int
+
+const (
+	stateClosed state = iota
+	stateOpen
+	stateHalfOpen
+)
+
+// Breaker opens after threshold consecutive failures. After the cooldown a
+// single probe call is allowed, closing the breaker on success.
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    state
+	failures int
+	openedAt time.Time
+}
+
+// New creates a closed breaker.
+func New(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+	}
+}
+
+// Allow returns ErrOpen if the call must not be made. Every allowed call must
+// be followed by Success, Failure or Ignore.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case stateOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return ErrOpen
+		}
+		b.state = stateHalfOpen
+		return nil
+	case stateHalfOpen:
+		// a probe is in flight
+		return ErrOpen
+	default:
+		return nil
+	}
+}
+
+// Success reports a successful call and closes the breaker.
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = stateClosed
+	b.failures = 0
+}
+
+// Failure reports a failed call and returns true if it opened the breaker.
+func (b *Breaker) Failure() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == stateHalfOpen || (b.state == stateClosed && b.failures >= b.threshold) {
+		b.state = stateOpen
+		b.openedAt = time.Now()
+		return true
+	}
+	return false
+}
+
+// Ignore reports a call which outcome says nothing about the dependency,
+// e.g. canceled by the caller. An ignored probe lets the next call probe again.
+func (b *Breaker) Ignore() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == stateHalfOpen {
+		b.state = stateOpen
+	}
+}
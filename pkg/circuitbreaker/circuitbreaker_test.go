@@ -0,0 +1,101 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBreakerOpensAfterThreshold(t *testing.T) {
+	b := New(3, time.Hour)
+
+	for i := 1; i < 3; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatalf("Allow() after %d failures = %v, want nil", i-1, err)
+		}
+		if b.Failure() {
+			t.Fatalf("Failure() %d opened the breaker, threshold is 3", i)
+		}
+	}
+
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow() after 2 failures = %v, want nil", err)
+	}
+	if !b.Failure() {
+		t.Fatal("Failure() 3 did not open the breaker")
+	}
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Fatalf("Allow() of an open breaker = %v, want %v", err, ErrOpen)
+	}
+}
+
+func TestBreakerSuccessResetsFailures(t *testing.T) {
+	b := New(2, time.Hour)
+
+	b.Failure()
+	b.Success()
+	if b.Failure() {
+		t.Fatal("Failure() opened the breaker, failures before success must not count")
+	}
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow() = %v, want nil", err)
+	}
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	open := func(t *testing.T) *Breaker {
+		b := New(1, 10*time.Millisecond)
+		b.Failure()
+		if err := b.Allow(); !errors.Is(err, ErrOpen) {
+			t.Fatalf("Allow() before cooldown = %v, want %v", err, ErrOpen)
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		if err := b.Allow(); err != nil {
+			t.Fatalf("Allow() of the probe after cooldown = %v, want nil", err)
+		}
+		if err := b.Allow(); !errors.Is(err, ErrOpen) {
+			t.Fatalf("Allow() while the probe is in flight = %v, want %v", err, ErrOpen)
+		}
+		return b
+	}
+
+	t.Run("probe success closes", func(t *testing.T) {
+		b := open(t)
+		b.Success()
+		for i := 0; i < 2; i++ {
+			if err := b.Allow(); err != nil {
+				t.Fatalf("Allow() of a closed breaker = %v, want nil", err)
+			}
+		}
+	})
+
+	t.Run("probe failure reopens", func(t *testing.T) {
+		b := open(t)
+		if !b.Failure() {
+			t.Fatal("Failure() of the probe did not reopen the breaker")
+		}
+		if err := b.Allow(); !errors.Is(err, ErrOpen) {
+			t.Fatalf("Allow() after the failed probe = %v, want %v", err, ErrOpen)
+		}
+	})
+
+	t.Run("ignored probe lets next call probe", func(t *testing.T) {
+		b := open(t)
+		b.Ignore()
+		if err := b.Allow(); err != nil {
+			t.Fatalf("Allow() after the ignored probe = %v, want nil", err)
+		}
+		if err := b.Allow(); !errors.Is(err, ErrOpen) {
+			t.Fatalf("Allow() while the next probe is in flight = %v, want %v", err, ErrOpen)
+		}
+	})
+}
+
+func TestBreakerIgnoreWhenClosed(t *testing.T) {
+	b := New(1, time.Hour)
+	b.Ignore()
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow() = %v, want nil", err)
+	}
+}
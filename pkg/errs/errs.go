@@ -1,22 +1,90 @@
 package errs
 
+import "errors"
+
+// Kind classifies expected errors, so transports can pick a matching status.
+type Kind string
+
+const (
+	KindValidation Kind = "validation"
+	KindNotFound   Kind = "not_found"
+	KindConflict   Kind = "conflict"
+	KindForbidden  Kind = "forbidden"
+	// KindUnauthenticated is an error of missing or invalid credentials.
+	KindUnauthenticated Kind = "unauthenticated"
+	KindUnavailable     Kind = "unavailable"
+)
+
 // Err implements the Error interface with error marshaling.
+// Code is a stable machine-readable identifier of the error.
 type Err struct {
+	Kind    Kind   `json:"kind"`
+	Code    string `json:"code"`
 	Message string `json:"message"`
 }
 
-func New(message string) error {
+func New(kind Kind, code, message string) error {
 	return &Err{
+		Kind:    kind,
+		Code:    code,
 		Message: message,
 	}
 }
 
+// Validation creates an error of invalid input.
+func Validation(code, message string) error {
+	return New(KindValidation, code, message)
+}
+
+// NotFound creates an error of a missing resource.
+func NotFound(code, message string) error {
+	return New(KindNotFound, code, message)
+}
+
+// Conflict creates an error of an operation conflicting with the current state.
+func Conflict(code, message string) error {
+	return New(KindConflict, code, message)
+}
+
+// Forbidden creates an error of an operation the caller is not allowed to do.
+func Forbidden(code, message string) error {
+	return New(KindForbidden, code, message)
+}
+
+// Unauthenticated creates an error of missing or invalid credentials.
+func Unauthenticated(code, message string) error {
+	return New(KindUnauthenticated, code, message)
+}
+
+// Unavailable creates an error of a temporarily unavailable dependency.
+func Unavailable(code, message string) error {
+	return New(KindUnavailable, code, message)
+}
+
 func (e *Err) Error() string {
 	return e.Message
 }
 
-// IsExpected finds Err{} inside passed error.
+// IsExpected finds Err{} inside passed error chain.
 func IsExpected(e error) bool {
-	_, ok := e.(*Err)
-	return ok
+	var err *Err
+	return errors.As(e, &err)
+}
+
+// KindOf returns kind of the first Err{} inside passed error chain, empty if there is none.
+func KindOf(e error) Kind {
+	var err *Err
+	if errors.As(e, &err) {
+		return err.Kind
+	}
+	return ""
+}
+
+// CodeOf returns code of the first Err{} inside passed error chain, empty if there is none.
+func CodeOf(e error) string {
+	var err *Err
+	if errors.As(e, &err) {
+		return err.Code
+	}
+	return ""
 }
@@ -22,6 +22,8 @@ type Server struct {
 	server          *http.Server
 	notify          chan error
 	shutdownTimeout time.Duration
+	// cancel - cancels contexts of all in-flight requests.
+	cancel context.CancelFunc
 }
 
 // Option - represents http server option.
@@ -65,10 +67,16 @@ func New(handler http.Handler, opts ...Option) *Server {
 		MaxHeaderBytes: _defaultMaxHeaderBytes,
 	}
 
+	baseCtx, cancel := context.WithCancel(context.Background())
+	httpServer.BaseContext = func(net.Listener) context.Context {
+		return baseCtx
+	}
+
 	s := &Server{
 		server:          httpServer,
 		notify:          make(chan error, 1),
 		shutdownTimeout: _defaultShutdownTimeout,
+		cancel:          cancel,
 	}
 
 	// add custom options
@@ -96,9 +104,11 @@ func (s *Server) Notify() <-chan error {
 }
 
 // Shutdown - shuts down http server gracefully.
+// Requests still running after shutdown timeout get their contexts canceled.
 func (s *Server) Shutdown() error {
 	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
 	defer cancel()
+	defer s.cancel()
 
 	return s.server.Shutdown(ctx)
 }
@@ -0,0 +1,70 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	// third party
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrCanceled is returned when a query is canceled, usually because the client went away.
+	ErrCanceled = errors.New("query canceled")
+	// ErrTimeout is returned when a query exceeds its statement timeout.
+	ErrTimeout = errors.New("query timed out")
+)
+
+// pgQueryCanceled is the SQLSTATE of a statement canceled by the server.
+const pgQueryCanceled = "57014"
+
+// pgStatementTimeoutMessage is the message of statements canceled by the server statement_timeout.
+const pgStatementTimeoutMessage = "canceling statement due to statement timeout"
+
+type statementTimeoutKey struct{}
+
+// WithStatementTimeout overrides the default statement timeout for queries made with returned context.
+// A zero timeout disables the timeout.
+func WithStatementTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, statementTimeoutKey{}, timeout)
+}
+
+// WithContext returns DB bound to ctx with the statement timeout applied.
+// The returned cancel func must be called once the query is done.
+func (p *PostgreSQLGorm) WithContext(ctx context.Context) (*gorm.DB, context.CancelFunc) {
+	timeout := p.StatementTimeout
+	if override, ok := ctx.Value(statementTimeoutKey{}).(time.Duration); ok {
+		timeout = override
+	}
+
+	if timeout <= 0 {
+		return p.DB.WithContext(ctx), func() {}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return p.DB.WithContext(ctx), cancel
+}
+
+// Error marks query errors caused by cancellation or timeout with ErrCanceled or ErrTimeout.
+// Other errors are returned as is.
+func Error(err error) error {
+	var pgErr *pgconn.PgError
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, context.Canceled):
+		return fmt.Errorf("%w: %w", ErrCanceled, err)
+	case errors.Is(err, context.DeadlineExceeded), pgconn.Timeout(err):
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	case errors.As(err, &pgErr) && pgErr.Code == pgQueryCanceled:
+		if pgErr.Message == pgStatementTimeoutMessage {
+			return fmt.Errorf("%w: %w", ErrTimeout, err)
+		}
+		return fmt.Errorf("%w: %w", ErrCanceled, err)
+	default:
+		return err
+	}
+}
@@ -2,6 +2,7 @@ package postgresql
 
 import (
 	"fmt"
+	"time"
 
 	// third party
 	"gorm.io/driver/postgres"
@@ -10,6 +11,8 @@ import (
 
 type PostgreSQLGorm struct {
 	DB *gorm.DB
+	// StatementTimeout is the default timeout of queries made via WithContext.
+	StatementTimeout time.Duration
 }
 
 type Config struct {
@@ -17,6 +20,9 @@ type Config struct {
 	Password string
 	Host     string
 	Database string
+	// StatementTimeout is the default query timeout, zero disables it.
+	// Use WithStatementTimeout to override it for a single operation.
+	StatementTimeout time.Duration
 }
 
 func NewPostgreSQLGorm(cfg Config) (*PostgreSQLGorm, error) {
@@ -32,6 +38,5 @@ func NewPostgreSQLGorm(cfg Config) (*PostgreSQLGorm, error) {
 		return nil, fmt.Errorf("failed to connect to postgresql: %s", err)
 	}
 
-
-	return &PostgreSQLGorm{DB: db}, nil
+	return &PostgreSQLGorm{DB: db, StatementTimeout: cfg.StatementTimeout}, nil
 }
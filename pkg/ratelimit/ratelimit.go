@@ -0,0 +1,148 @@
+// Package ratelimit implements token bucket rate limiting of requests by key.
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limit allows Requests per Period with bursts of up to Requests.
+// Limit with zero Requests is unlimited.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// ParseLimit parses a limit given as <requests>/<period>, e.g. 100/1m. "0" is unlimited,
+// zero requests of a period are rejected.
+func ParseLimit(value string) (Limit, error) {
+	value = strings.TrimSpace(value)
+	if value == "0" {
+		return Limit{}, nil
+	}
+
+	requests, period, ok := strings.Cut(value, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: must be <requests>/<period>", value)
+	}
+
+	n, err := strconv.Atoi(requests)
+	if err != nil || n <= 0 {
+		// zero requests of a period would silently disable the limit, "0" must be used instead
+		return Limit{}, fmt.Errorf("invalid rate limit %q: requests must be a positive number", value)
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: period must be a positive duration", value)
+	}
+	if d/time.Duration(n) == 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: period is too short for the number of requests", value)
+	}
+	return Limit{Requests: n, Period: d}, nil
+}
+
+// Unlimited reports whether the limit allows all requests.
+func (l Limit) Unlimited() bool {
+	return l.Requests == 0
+}
+
+func (l Limit) String() string {
+	if l.Unlimited() {
+		return "0"
+	}
+	return fmt.Sprintf("%d/%s", l.Requests, l.Period)
+}
+
+// Result describes the bucket of a key after a request.
+type Result struct {
+	Allowed bool
+	// Limit is the bucket capacity and Remaining is the number of requests allowed right now.
+	Limit     int
+	Remaining int
+	// RetryAfter is the time until the next request is allowed, zero if it is allowed now.
+	RetryAfter time.Duration
+	// ResetAfter is the time until the bucket is full again.
+	ResetAfter time.Duration
+}
+
+// Limiter limits requests of every key by its own token bucket. It is safe for concurrent use.
+type Limiter struct {
+	limit Limit
+	// interval is the time to refill a single token.
+	interval time.Duration
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// New creates a limiter of the limit.
+func New(limit Limit) *Limiter {
+	l := &Limiter{
+		limit:     limit,
+		buckets:   map[string]*bucket{},
+		lastSweep: time.Now(),
+	}
+	if !limit.Unlimited() {
+		l.interval = limit.Period / time.Duration(limit.Requests)
+	}
+	return l
+}
+
+// Allow takes a token of the key bucket if there is one.
+func (l *Limiter) Allow(key string) Result {
+	if l.limit.Unlimited() {
+		return Result{Allowed: true}
+	}
+
+	now := time.Now()
+	capacity := float64(l.limit.Requests)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updatedAt: now}
+		l.buckets[key] = b
+	}
+	b.tokens = min(capacity, b.tokens+float64(now.Sub(b.updatedAt))/float64(l.interval))
+	b.updatedAt = now
+
+	result := Result{Limit: l.limit.Requests}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - b.tokens) * float64(l.interval))
+	}
+	result.Remaining = int(math.Floor(b.tokens))
+	result.ResetAfter = time.Duration((capacity - b.tokens) * float64(l.interval))
+	return result
+}
+
+// sweep forgets buckets refilled since their last request once a period, so idle keys do not pile up.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.limit.Period {
+		return
+	}
+	l.lastSweep = now
+
+	capacity := float64(l.limit.Requests)
+	for key, b := range l.buckets {
+		if b.tokens+float64(now.Sub(b.updatedAt))/float64(l.interval) >= capacity {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Limit
+		wantErr bool
+	}{
+		{value: "0", want: Limit{}},
+		{value: " 0 ", want: Limit{}},
+		{value: "100/1m", want: Limit{Requests: 100, Period: time.Minute}},
+		{value: "5/30s", want: Limit{Requests: 5, Period: 30 * time.Second}},
+		{value: "", wantErr: true},
+		{value: "100", wantErr: true},
+		{value: "many/1m", wantErr: true},
+		{value: "0/1m", wantErr: true},
+		{value: "-1/1m", wantErr: true},
+		{value: "100/minute", wantErr: true},
+		{value: "100/0s", wantErr: true},
+		{value: "100/-1m", wantErr: true},
+		{value: "2/1ns", wantErr: true},
+		{value: "1000/1us", want: Limit{Requests: 1000, Period: time.Microsecond}},
+	}
+	for _, tt := range tests {
+		got, err := ParseLimit(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLimit(%q) error = %v, want error %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseLimit(%q) = %+v, want %+v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestLimitString(t *testing.T) {
+	for _, value := range []string{"0", "100/1m0s", "5/30s"} {
+		limit, err := ParseLimit(value)
+		if err != nil {
+			t.Fatalf("ParseLimit(%q) error = %v", value, err)
+		}
+		if got := limit.String(); got != value {
+			t.Errorf("ParseLimit(%q).String() = %q", value, got)
+		}
+	}
+}
+
+func TestLimiterUnlimited(t *testing.T) {
+	l := New(Limit{})
+	for i := 0; i < 1000; i++ {
+		if result := l.Allow("key"); !result.Allowed {
+			t.Fatalf("Allow() %d = %+v, want allowed", i, result)
+		}
+	}
+}
+
+func TestLimiterAllow(t *testing.T) {
+	l := New(Limit{Requests: 3, Period: time.Hour})
+
+	for i := 0; i < 3; i++ {
+		result := l.Allow("a")
+		if !result.Allowed || result.Limit != 3 || result.Remaining != 2-i || result.RetryAfter != 0 {
+			t.Fatalf("Allow() %d = %+v, want allowed with %d remaining", i, result, 2-i)
+		}
+	}
+
+	result := l.Allow("a")
+	if result.Allowed || result.Remaining != 0 {
+		t.Fatalf("Allow() over the limit = %+v, want denied", result)
+	}
+	// a token is refilled every 20 minutes
+	if result.RetryAfter <= 19*time.Minute || result.RetryAfter > 20*time.Minute {
+		t.Fatalf("Allow().RetryAfter = %v, want about 20m", result.RetryAfter)
+	}
+	if result.ResetAfter <= 59*time.Minute || result.ResetAfter > time.Hour {
+		t.Fatalf("Allow().ResetAfter = %v, want about 1h", result.ResetAfter)
+	}
+
+	if result := l.Allow("b"); !result.Allowed || result.Remaining != 2 {
+		t.Fatalf("Allow() of another key = %+v, want allowed with 2 remaining", result)
+	}
+}
+
+func TestLimiterRefill(t *testing.T) {
+	l := New(Limit{Requests: 2, Period: 40 * time.Millisecond})
+
+	l.Allow("a")
+	l.Allow("a")
+	if result := l.Allow("a"); result.Allowed {
+		t.Fatalf("Allow() over the limit = %+v, want denied", result)
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	if result := l.Allow("a"); !result.Allowed || result.Remaining != 0 {
+		t.Fatalf("Allow() after a token refill = %+v, want allowed with 0 remaining", result)
+	}
+}
+
+func TestLimiterSweep(t *testing.T) {
+	l := New(Limit{Requests: 2, Period: 20 * time.Millisecond})
+
+	l.Allow("idle")
+	l.Allow("busy")
+	time.Sleep(25 * time.Millisecond)
+	l.Allow("busy")
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.buckets["idle"]; ok {
+		t.Fatal("bucket of an idle key was not swept")
+	}
+	if _, ok := l.buckets["busy"]; !ok {
+		t.Fatal("bucket of a busy key was swept")
+	}
+}